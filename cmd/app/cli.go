@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+)
+
+const cliUsage = `Usage:
+  go-ch-manager                                  start the web server
+  go-ch-manager connections export [flags]       write saved connections to a file
+      -format yaml|json   file format (default yaml, or taken from the -o extension)
+      -o path             output file (default stdout)
+      -secrets            include plaintext passwords
+  go-ch-manager connections import [flags] FILE  upsert connections by name, "-" reads stdin
+      -overwrite          replace saved connections that differ (default true)
+`
+
+// runCommand handles the provisioning subcommands and returns the process exit code
+func runCommand(args []string, connectionUsecase *usecase.ConnectionUsecase) int {
+	if len(args) < 2 || args[0] != "connections" {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+
+	var err error
+	switch args[1] {
+	case "export":
+		err = exportConnectionsCommand(args[2:], connectionUsecase)
+	case "import":
+		err = importConnectionsCommand(args[2:], connectionUsecase)
+	default:
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+func exportConnectionsCommand(args []string, connectionUsecase *usecase.ConnectionUsecase) error {
+	flags := flag.NewFlagSet("connections export", flag.ContinueOnError)
+	format := flags.String("format", "", "yaml or json")
+	output := flags.String("o", "", "output file")
+	withSecrets := flags.Bool("secrets", false, "include plaintext passwords")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*output), ".")
+		if *format == "" {
+			*format = "yaml"
+		}
+	}
+
+	file, err := connectionUsecase.ExportConnections(context.Background(), *withSecrets)
+	if err != nil {
+		return err
+	}
+
+	data, err := usecase.MarshalConnectionProfiles(file, *format)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	// Exports with secrets hold plaintext passwords, keep them private to the owner
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d connection(s) to %s\n", len(file.Connections), *output)
+	return nil
+}
+
+func importConnectionsCommand(args []string, connectionUsecase *usecase.ConnectionUsecase) error {
+	flags := flag.NewFlagSet("connections import", flag.ContinueOnError)
+	overwrite := flags.Bool("overwrite", true, "replace saved connections that differ")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected exactly one file to import")
+	}
+
+	var data []byte
+	var err error
+	if path := flags.Arg(0); path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := usecase.UnmarshalConnectionProfiles(data)
+	if err != nil {
+		return err
+	}
+
+	result, err := connectionUsecase.ImportConnections(context.Background(), file, *overwrite)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range result.Entries {
+		if entry.Message != "" {
+			fmt.Printf("%-10s %s: %s\n", entry.Status, entry.Name, entry.Message)
+		} else {
+			fmt.Printf("%-10s %s\n", entry.Status, entry.Name)
+		}
+	}
+	fmt.Printf("\n%d created, %d updated, %d unchanged, %d conflicts, %d failed\n",
+		result.Created, result.Updated, result.Unchanged, result.Conflicts, result.Failed)
+
+	if result.Conflicts > 0 || result.Failed > 0 {
+		return fmt.Errorf("%d connection(s) were not imported", result.Conflicts+result.Failed)
+	}
+	return nil
+}
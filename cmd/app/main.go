@@ -22,7 +22,9 @@ import (
 	"github.com/rahmatrdn/go-ch-manager/internal/parser"
 	"github.com/rahmatrdn/go-ch-manager/internal/presenter/json"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/llm"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/sqlite"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
 	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
 	gormsqlite "gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -95,28 +97,83 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to connect to SQLite:", err)
 	}
+	// Before tls_skip_verify existed UseSSL skipped the certificate verification, keep those connections working
+	legacyTLS := !sqliteDB.Migrator().HasColumn(&entity.CHConnection{}, "TLSSkipVerify")
+
 	// Migrate
-	sqliteDB.AutoMigrate(&entity.CHConnection{}, &entity.SlowQueryReport{}, &entity.QueryHistory{}, &entity.FavoriteComparison{})
+	sqliteDB.AutoMigrate(&entity.CHConnection{}, &entity.SlowQueryReport{}, &entity.QueryHistory{}, &entity.FavoriteComparison{}, &entity.ConnectionHealth{}, &entity.AIAnalysisCache{})
+	if legacyTLS {
+		if err := sqliteDB.Model(&entity.CHConnection{}).Where("use_ssl = ?", true).Update("tls_skip_verify", true).Error; err != nil {
+			log.Fatal("Failed to migrate TLS settings:", err)
+		}
+	}
+
+	// Secrets: ClickHouse passwords are encrypted at rest with the master key
+	masterKey, err := secret.LoadKey(cfg.MasterKey, cfg.MasterKeyFile)
+	if err != nil {
+		log.Fatal("Failed to load master key:", err)
+	}
+	cipher, err := secret.NewCipher(masterKey)
+	if err != nil {
+		log.Fatal("Failed to initialize cipher:", err)
+	}
 
 	// CH Manager Dependencies
-	chClient := clickhouse.NewClickHouseClient()
+	chClient := clickhouse.NewClickHouseClient(cipher, time.Duration(cfg.PoolIdleTTL)*time.Second)
+	defer chClient.Close()
 	connectionRepo := sqlite.NewConnectionRepository(sqliteDB)
 	historyRepo := sqlite.NewQueryHistoryRepository(sqliteDB)
 	favRepo := sqlite.NewFavoriteRepository(sqliteDB)
 	reportRepo := sqlite.NewReportRepository(sqliteDB)
-	connectionUsecase := usecase.NewConnectionUsecase(connectionRepo, historyRepo, favRepo, chClient)
+	connectionUsecase := usecase.NewConnectionUsecase(connectionRepo, historyRepo, favRepo, chClient, cipher, entity.QueryOptions{
+		MaxResultRows:  cfg.QueryMaxResultRows,
+		MaxResultBytes: cfg.QueryMaxResultBytes,
+	}, cfg.QueryExportMaxBytes)
+
+	// Encrypt passwords saved by older versions
+	if migrated, err := connectionUsecase.EncryptStoredPasswords(context.Background()); err != nil {
+		log.Fatal("Failed to encrypt stored passwords:", err)
+	} else if migrated > 0 {
+		log.Printf("Encrypted %d stored connection password(s)\n", migrated)
+	}
+
+	// Provisioning subcommands share the same store, they run instead of the server
+	if len(os.Args) > 1 {
+		code := runCommand(os.Args[1:], connectionUsecase)
+		chClient.Close()
+		os.Exit(code)
+	}
+
+	healthRepo := sqlite.NewHealthRepository(sqliteDB)
 	reportUsecase := usecase.NewReportUsecase(reportRepo, connectionRepo, chClient)
+	healthUsecase := usecase.NewHealthUsecase(healthRepo, connectionRepo, chClient)
+
+	// AI analysis, only when a model is configured
+	var analyzer usecase.Analyzer
+	if cfg.LLMBaseURL != "" {
+		analyzer = llm.NewOpenAIAnalyzer(cfg.LLMBaseURL, cfg.LLMAPIKey, cfg.LLMModel, cfg.LLMMaxTokens, time.Duration(cfg.LLMTimeout)*time.Second)
+	}
+	advisorUsecase := usecase.NewAdvisorUsecase(connectionUsecase, analyzer, sqlite.NewAIAnalysisRepository(sqliteDB), cfg.LLMMaxPromptTokens, time.Duration(cfg.LLMCacheTTL)*time.Hour)
+
+	// Background health monitor, stopped together with the server
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	if cfg.HealthCheckInterval > 0 {
+		go healthUsecase.Run(monitorCtx, time.Duration(cfg.HealthCheckInterval)*time.Second)
+	}
 
 	api := app.Group("/api/v1")
 
 	handler.NewConnectionHandler(parser, presenterJson, connectionUsecase).Register(api)
+	handler.NewHealthHandler(presenterJson, healthUsecase).Register(api)
+	handler.NewAdvisorHandler(presenterJson, advisorUsecase).Register(api)
 
 	// Register Report Handler
 	handler.NewReportHandler(reportUsecase, connectionUsecase).Register(app)
 
 	// Register View Handler (MPA)
 	// Note: View routes are correctly registered at root level by this handler
-	handler.NewViewHandler(connectionUsecase).Register(app)
+	handler.NewViewHandler(connectionUsecase, healthUsecase).Register(app)
 
 	// Serve Frontend (Assets only if needed, root is now handled by view handler)
 	// app.Static("/", "./internal/views")
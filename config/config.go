@@ -14,6 +14,20 @@ type Config struct {
 	AllowedCredentialOrigins []string `env:"ALLOWED_CREDENTIAL_ORIGINS"`
 	MiddlewareAddress        string   `env:"MIDDLEWARE_ADDR"`
 	JwtExpireDaysCount       int      `env:"JWT_EXPIRE_DAYS_COUNT"`
+	MasterKey                string   `env:"MASTER_KEY"`
+	MasterKeyFile            string   `env:"MASTER_KEY_FILE,default=database/sqlite/master.key"`
+	HealthCheckInterval      uint     `env:"HEALTH_CHECK_INTERVAL_SECONDS,default=60"`
+	PoolIdleTTL              uint     `env:"CH_POOL_IDLE_TTL_SECONDS,default=300"`
+	QueryMaxResultRows       uint64   `env:"QUERY_MAX_RESULT_ROWS,default=100000"`
+	QueryMaxResultBytes      uint64   `env:"QUERY_MAX_RESULT_BYTES,default=104857600"`
+	QueryExportMaxBytes      uint64   `env:"QUERY_EXPORT_MAX_BYTES,default=536870912"`
+	LLMBaseURL               string   `env:"LLM_BASE_URL"`
+	LLMAPIKey                string   `env:"LLM_API_KEY"`
+	LLMModel                 string   `env:"LLM_MODEL,default=gpt-4o-mini"`
+	LLMMaxTokens             int      `env:"LLM_MAX_TOKENS,default=1500"`
+	LLMMaxPromptTokens       int      `env:"LLM_MAX_PROMPT_TOKENS,default=16000"`
+	LLMTimeout               uint     `env:"LLM_TIMEOUT_SECONDS,default=120"`
+	LLMCacheTTL              uint     `env:"LLM_CACHE_TTL_HOURS,default=168"`
 }
 
 func NewConfig() *Config {
@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// Impact of an AI recommendation
+const (
+	AIImpactHigh   = "high"
+	AIImpactMedium = "medium"
+	AIImpactLow    = "low"
+)
+
+// Types of AIAnalysisChunk
+const (
+	AIChunkDelta  = "delta"
+	AIChunkResult = "result"
+	AIChunkErr    = "error"
+)
+
+// AIAnalysisRequest asks a language model about a query, the query is analyzed first the way AnalyzeRequest says.
+// Refresh skips the cached answer.
+type AIAnalysisRequest struct {
+	AnalyzeRequest
+	Refresh bool `json:"refresh"`
+}
+
+// AICompletion is the answer of a language model, Truncated is set when it stopped at the token limit
+type AICompletion struct {
+	Content          string
+	PromptTokens     int
+	CompletionTokens int
+	Truncated        bool
+}
+
+// AIRecommendation is one recommendation read from the answer, SQL holds the rewrite or DDL it proposes
+type AIRecommendation struct {
+	Impact string `json:"impact"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	SQL    string `json:"sql,omitempty"`
+}
+
+// AIAnalysis is the advice of a language model on a query, Content is its whole markdown answer
+type AIAnalysis struct {
+	Model            string             `json:"model"`
+	Content          string             `json:"content"`
+	Recommendations  []AIRecommendation `json:"recommendations"`
+	PromptTokens     int                `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                `json:"completion_tokens,omitempty"`
+	Truncated        bool               `json:"truncated,omitempty"`
+	Cached           bool               `json:"cached"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+// AIAnalysisChunk is one line of a streamed AI analysis: the answer in deltas as it is generated, then the result
+// (or error)
+type AIAnalysisChunk struct {
+	Type   string      `json:"type"`
+	Delta  string      `json:"delta,omitempty"`
+	Result *AIAnalysis `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Code   string      `json:"code,omitempty"`
+}
+
+// AIAnalysisCache keeps the answer for a normalized query, CacheKey also covers the connection, model and mode
+type AIAnalysisCache struct {
+	ID               int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConnectionID     int64     `gorm:"index;not null" json:"connection_id"`
+	CacheKey         string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"cache_key"`
+	Model            string    `gorm:"type:varchar(100)" json:"model"`
+	NormalizedQuery  string    `gorm:"type:text" json:"normalized_query"`
+	Content          string    `gorm:"type:text" json:"content"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Truncated        bool      `json:"truncated"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by AIAnalysisCache to `ai_analysis_cache`
+func (AIAnalysisCache) TableName() string {
+	return "ai_analysis_cache"
+}
@@ -1,6 +1,24 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Open strategies for connections with several endpoints (replicas)
+const (
+	ConnOpenInOrder    = "in_order"
+	ConnOpenRoundRobin = "round_robin"
+	ConnOpenRandom     = "random"
+)
+
+// Connection labels, PRODUCTION ones refuse statements that change the server until they are confirmed
+const (
+	LabelDevelopment = "DEVELOPMENT"
+	LabelStaging     = "STAGING"
+	LabelProduction  = "PRODUCTION"
+)
 
 type CHConnection struct {
 	ID         int64  `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -15,10 +33,65 @@ type CHConnection struct {
 	ServerInfo string `json:"server_info" gorm:"type:text"`
 	Label      string `json:"label" gorm:"type:varchar(20);default:'DEVELOPMENT'"`
 
+	// Hosts lists additional replica endpoints ("host:port", comma or newline separated) next to Host/Port
+	Hosts            string `json:"hosts" form:"hosts" gorm:"type:text"`
+	ConnOpenStrategy string `json:"conn_open_strategy" form:"conn_open_strategy" gorm:"type:varchar(20);default:'in_order'"`
+
+	// TLS settings, only used when UseSSL is enabled. Cert fields are file paths to PEM files.
+	TLSCACert     string `json:"tls_ca_cert" form:"tls_ca_cert" gorm:"type:text"`
+	TLSClientCert string `json:"tls_client_cert" form:"tls_client_cert" gorm:"type:text"`
+	TLSClientKey  string `json:"tls_client_key" form:"tls_client_key" gorm:"type:text"`
+	TLSServerName string `json:"tls_server_name" form:"tls_server_name" gorm:"type:varchar(255)"`
+	TLSSkipVerify bool   `json:"tls_skip_verify" gorm:"default:false"`
+
+	// Pool limits for this connection, 0 means the driver default
+	MaxOpenConns int `json:"max_open_conns" form:"max_open_conns"`
+	MaxIdleConns int `json:"max_idle_conns" form:"max_idle_conns"`
+
+	// HasPassword is only filled on redacted copies so the UI knows a password is stored
+	HasPassword bool `json:"has_password" gorm:"-"`
+	// ClearPassword removes the stored password on update, an empty Password keeps it
+	ClearPassword bool `json:"clear_password" gorm:"-"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Endpoints returns every address of the connection, the primary Host/Port first.
+// Extra hosts without a port reuse the primary port.
+func (c *CHConnection) Endpoints() []string {
+	endpoints := []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
+	seen := map[string]bool{endpoints[0]: true}
+
+	for _, host := range strings.FieldsFunc(c.Hosts, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == ';'
+	}) {
+		if !strings.Contains(host, ":") {
+			host = fmt.Sprintf("%s:%d", host, c.Port)
+		}
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		endpoints = append(endpoints, host)
+	}
+
+	return endpoints
+}
+
+// IsProduction reports whether the connection is labelled PRODUCTION
+func (c *CHConnection) IsProduction() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Label), LabelProduction)
+}
+
+// Redact returns a copy without the stored (encrypted) password, safe to send to the browser
+func (c *CHConnection) Redact() *CHConnection {
+	redacted := *c
+	redacted.HasPassword = c.Password != ""
+	redacted.Password = ""
+	return &redacted
+}
+
 type TableSchema struct {
 	Name     string              `json:"name"`
 	Database string              `json:"database"`
@@ -39,20 +112,129 @@ type QueryStats struct {
 	ExecutionTimeMs int64  `json:"execution_time_ms"`
 	RowsRead        uint64 `json:"rows_read"`
 	BytesRead       uint64 `json:"bytes_read"`
+	ResultRows      uint64 `json:"result_rows"`
 	MemoryPeak      uint64 `json:"memory_peak"`
 	PartsRead       uint64 `json:"parts_read"`
 	MarksRead       uint64 `json:"marks_read"`
+	// CPU time summed over every thread of the query, in microseconds
+	UserTimeUs   uint64 `json:"user_time_us"`
+	SystemTimeUs uint64 `json:"system_time_us"`
+	Threads      uint64 `json:"threads"`
+	// Bytes the server received from and sent to the network, other shards included
+	NetworkReceiveBytes uint64 `json:"network_receive_bytes"`
+	NetworkSendBytes    uint64 `json:"network_send_bytes"`
+	MarkCacheHits       uint64 `json:"mark_cache_hits"`
+	MarkCacheMisses     uint64 `json:"mark_cache_misses"`
+	QueryCacheHits      uint64 `json:"query_cache_hits"`
+	QueryCacheMisses    uint64 `json:"query_cache_misses"`
+	ServedBy            string `json:"served_by"` // hostname of the replica that logged the query, not the one asked for the stats
+	// Queries the initial one sent to other servers, their counters are summed in
+	SecondaryQueries uint64 `json:"secondary_queries"`
+	// Every ProfileEvents counter of the query_log entries, the fields above included
+	ProfileEvents map[string]uint64 `json:"profile_events,omitempty"`
+	// Unavailable when the query_log entry could not be found, only the client side duration is known
+	Unavailable bool `json:"unavailable,omitempty"`
+	// Partial when the query was stopped at its read limits (or may have been, when Unavailable), the stats cover what it read until then
+	Partial bool `json:"partial,omitempty"`
 }
 
 type QueryResult struct {
-	Columns []string                 `json:"columns"`
-	Rows    []map[string]interface{} `json:"rows"`
-	Stats   *QueryStats              `json:"stats"`
+	QueryID   string                   `json:"query_id"`
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Stats     *QueryStats              `json:"stats"`
+	Truncated bool                     `json:"truncated"` // the server stopped at the result limits
+}
+
+// QueryOptions tune a single execution, zero values keep the server defaults
+type QueryOptions struct {
+	// Applied as max_result_rows / max_result_bytes with result_overflow_mode = 'break',
+	// the server stops sending rows and the result is flagged as truncated
+	MaxResultRows  uint64
+	MaxResultBytes uint64
+	// QueryID tags the execution so it can be cancelled, a random one is used when empty
+	QueryID string
+	// Parameters bind the {name:Type} placeholders of the query on the server
+	Parameters map[string]string
+	// Settings are per-execution overrides (max_threads, max_memory_usage...), the limits and ReadOnly win over them
+	Settings map[string]string
+	// ReadOnly runs the query with readonly = 1, the server refuses anything that writes or changes settings
+	ReadOnly bool
+	// Applied as max_rows_to_read / max_execution_time (seconds) with read_overflow_mode and timeout_overflow_mode = 'break',
+	// the server stops reading and returns what it computed so far
+	MaxRowsToRead    uint64
+	MaxExecutionTime uint64
+}
+
+// QueryRequest is a console execution as sent by the browser
+type QueryRequest struct {
+	QueryID    string            `json:"query_id"` // optional UUID, lets the caller cancel before the response arrives
+	Query      string            `json:"query"`
+	Parameters map[string]string `json:"params"`   // values of the {name:Type} placeholders
+	Settings   map[string]string `json:"settings"` // ClickHouse settings applied to this execution only
+	Confirm    bool              `json:"confirm"`  // runs DDL/DML on a PRODUCTION connection
+}
+
+// Chunk types of a streamed query result, sent as newline delimited JSON
+const (
+	QueryChunkProgress = "progress"
+	QueryChunkMeta     = "meta"
+	QueryChunkRows     = "rows"
+	QueryChunkEnd      = "end"
+	QueryChunkErr      = "error"
+)
+
+// QueryChunk is one line of a streamed result: progress while the query starts, meta, then rows, then end (or error).
+// End carries a cursor when more rows are waiting, or the stats once the result is exhausted.
+type QueryChunk struct {
+	Type      string          `json:"type"`
+	QueryID   string          `json:"query_id,omitempty"`
+	Columns   []string        `json:"columns,omitempty"`
+	Rows      [][]interface{} `json:"rows,omitempty"`
+	Cursor    string          `json:"cursor,omitempty"`
+	HasMore   bool            `json:"has_more,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+	RowCount  uint64          `json:"row_count,omitempty"`
+	Stats     *QueryStats     `json:"stats,omitempty"`
+	ElapsedMs int64           `json:"elapsed_ms,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Code      string          `json:"code,omitempty"` // error code, tells a refused statement from a failed one
+}
+
+// QueryCancelResult tells how far a cancellation got
+type QueryCancelResult struct {
+	QueryID string `json:"query_id"`
+	// Cancelled is set when the query was started by this server and its execution was stopped here
+	Cancelled bool `json:"cancelled"`
+	// Killed is set when the server answered KILL QUERY with a matching running query
+	Killed bool `json:"killed"`
+}
+
+// Outcome of a statement of a script
+const (
+	StatementSuccess = "success"
+	StatementError   = "error"
+	StatementSkipped = "skipped"
+)
+
+// ScriptResult holds every statement of a multi-statement script in order
+type ScriptResult struct {
+	QueryID    string            `json:"query_id"` // cancels the whole script
+	Statements []StatementResult `json:"statements"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Skipped    int               `json:"skipped"`
 }
 
-type CompareResult struct {
-	Query1Stats *QueryStats `json:"query1_stats"`
-	Query2Stats *QueryStats `json:"query2_stats"`
+type StatementResult struct {
+	Index  int    `json:"index"`
+	Line   int    `json:"line"`
+	Query  string `json:"query"`
+	Status string `json:"status"`
+	// Result of statements returning rows, a preview when the result is long
+	Result *QueryResult `json:"result,omitempty"`
+	Stats  *QueryStats  `json:"stats,omitempty"`
+	Error  string       `json:"error,omitempty"`
 }
 
 type TableSchemaInfo struct {
@@ -62,11 +244,138 @@ type TableSchemaInfo struct {
 	HasWarning bool   `json:"has_warning"`
 }
 
+// How AnalyzeQuery gets the stats of the query
+const (
+	AnalyzeModeFull    = "full"    // runs the query to the end
+	AnalyzeModeDryRun  = "dry_run" // never runs it, EXPLAIN ESTIMATE and the plan predict what it reads
+	AnalyzeModeSampled = "sampled" // runs it under read and time limits, the stats may be partial
+)
+
+// AnalyzeRequest is a console query to analyze, the sample limits only apply to the sampled mode and have defaults
+type AnalyzeRequest struct {
+	QueryRequest
+	Mode          string `json:"mode"`
+	SampleRows    uint64 `json:"sample_rows"`
+	SampleSeconds uint64 `json:"sample_seconds"`
+}
+
+// QueryEstimate is what a query is expected to read, from EXPLAIN ESTIMATE or, when the server cannot estimate,
+// from the parts and granules the indexes of the plan selected. Rows are unknown from a plan.
+type QueryEstimate struct {
+	Source string            `json:"source"` // estimate or plan
+	Rows   uint64            `json:"rows"`
+	Parts  uint64            `json:"parts"`
+	Marks  uint64            `json:"marks"`
+	Tables []ExplainEstimate `json:"tables"`
+}
+
 type QueryAnalysis struct {
-	Query        string            `json:"query"`
-	Tables       []TableSchemaInfo `json:"tables"`
-	ExplainPlan  string            `json:"explain_plan"`
-	QueryStats   *QueryStats       `json:"query_stats"`
-	AnalysisText string            `json:"analysis_text"`
-	Warnings     []string          `json:"warnings"`
+	Query  string            `json:"query"`
+	Tables []TableSchemaInfo `json:"tables"`
+	// What else the query refers to, columns are qualified with their table when the query does
+	TableFunctions []string    `json:"table_functions"`
+	Dictionaries   []string    `json:"dictionaries"`
+	Columns        []string    `json:"columns"`
+	ExplainPlan    string      `json:"explain_plan"`
+	Mode           string      `json:"mode"`
+	QueryStats     *QueryStats `json:"query_stats"` // nil in a dry run
+	// What the query is expected to read, for the dry and sampled runs
+	Estimate *QueryEstimate `json:"estimate,omitempty"`
+	// The limits of a sampled run
+	SampleRows    uint64   `json:"sample_rows,omitempty"`
+	SampleSeconds uint64   `json:"sample_seconds,omitempty"`
+	AnalysisText  string   `json:"analysis_text"`
+	Warnings      []string `json:"warnings"`
+	// Best practices the query or its tables break, most severe first
+	Findings []LintFinding `json:"findings"`
+}
+
+// Kinds of EXPLAIN the console can run
+const (
+	ExplainKindPlan      = "plan"       // EXPLAIN PLAN indexes = 1, actions = 1, read as JSON
+	ExplainKindPipeline  = "pipeline"   // EXPLAIN PIPELINE
+	ExplainKindEstimate  = "estimate"   // EXPLAIN ESTIMATE, rows, parts and marks per table
+	ExplainKindSyntax    = "syntax"     // EXPLAIN SYNTAX, the query as the server rewrites it
+	ExplainKindQueryTree = "query_tree" // EXPLAIN QUERY TREE, needs the analyzer
+)
+
+// ExplainRequest explains a console query, Kind defaults to plan
+type ExplainRequest struct {
+	Query      string            `json:"query"`
+	Parameters map[string]string `json:"params"`
+	Settings   map[string]string `json:"settings"`
+	Kind       string            `json:"kind"`
+}
+
+// ExplainResult is the output of an EXPLAIN. Text is what the server printed, a plan is printed back from its JSON.
+// Tree nests the steps of a plan, a pipeline or a query tree, Estimates holds the rows of EXPLAIN ESTIMATE.
+type ExplainResult struct {
+	Kind      string            `json:"kind"`
+	Text      string            `json:"text"`
+	Tree      []*ExplainNode    `json:"tree,omitempty"`
+	Estimates []ExplainEstimate `json:"estimates,omitempty"`
+}
+
+// ExplainNode is a step of a plan, a pipeline or a query tree
+type ExplainNode struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"` // the other fields of a JSON plan step
+	Indexes     []ExplainIndex         `json:"indexes,omitempty"`
+	Children    []*ExplainNode         `json:"children,omitempty"`
+}
+
+// ExplainIndex is how an index pruned the parts and granules a plan step reads
+type ExplainIndex struct {
+	Type             string   `json:"type"` // MinMax, Partition, PrimaryKey or Skip
+	Name             string   `json:"name,omitempty"`
+	Keys             []string `json:"keys,omitempty"`
+	Condition        string   `json:"condition,omitempty"`
+	InitialParts     uint64   `json:"initial_parts"`
+	SelectedParts    uint64   `json:"selected_parts"`
+	InitialGranules  uint64   `json:"initial_granules"`
+	SelectedGranules uint64   `json:"selected_granules"`
+}
+
+// ExplainEstimate is what a query would read from a table
+type ExplainEstimate struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Parts    uint64 `json:"parts"`
+	Rows     uint64 `json:"rows"`
+	Marks    uint64 `json:"marks"`
+}
+
+// Severities of a lint finding
+const (
+	LintSeverityCritical = "critical"
+	LintSeverityWarning  = "warning"
+	LintSeverityInfo     = "info"
+)
+
+// LintFinding is a best practice rule a query breaks, RuleID names the rule in the clickhouse-best-practices skill
+type LintFinding struct {
+	RuleID     string `json:"rule_id"`
+	Severity   string `json:"severity"`
+	Table      string `json:"table,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// Stages reported by a connection test, in the order they run
+const (
+	ConnTestStageConfig     = "config"
+	ConnTestStagePing       = "ping"
+	ConnTestStageServerInfo = "server_info"
+	ConnTestStageDone       = "done"
+)
+
+// ConnectionTestResult holds the diagnostics of testing an unsaved connection
+type ConnectionTestResult struct {
+	Success       bool     `json:"success"`
+	Stage         string   `json:"stage"`
+	Endpoints     []string `json:"endpoints"`
+	LatencyMs     int64    `json:"latency_ms"`
+	ServerVersion string   `json:"server_version,omitempty"`
+	Error         string   `json:"error,omitempty"`
 }
@@ -0,0 +1,111 @@
+package entity
+
+// CompareRequest is a comparison as sent by the compare page, every query shares the parameters and settings.
+// Candidates lists the queries to compare, Query1 and Query2 are used when it is empty.
+type CompareRequest struct {
+	Query1     string             `json:"query1"`
+	Query2     string             `json:"query2"`
+	Candidates []CompareCandidate `json:"candidates"`
+	Parameters map[string]string  `json:"params"`
+	Settings   map[string]string  `json:"settings"`
+	// Iterations are the measured runs per query, 1 when unset
+	Iterations int `json:"iterations"`
+	// WarmUp runs go before the measured ones, their stats are dropped
+	WarmUp int `json:"warm_up"`
+	// DropCaches clears the mark, uncompressed and query caches before every measured run
+	DropCaches bool `json:"drop_caches"`
+	// CheckResults runs every query once more to tell whether it returns the same rows as the first one,
+	// reporting how it differs when it does not
+	CheckResults bool `json:"check_results"`
+	Confirm      bool `json:"confirm"` // drops the caches of a PRODUCTION connection
+}
+
+// CompareCandidate is one query of a comparison, the first candidate is the baseline of the others
+type CompareCandidate struct {
+	Label string `json:"label"`
+	Query string `json:"query"`
+	// ConnectionID runs the query on another connection than the one compared from, to compare servers
+	ConnectionID int64 `json:"connection_id"`
+}
+
+type CompareResult struct {
+	// Median runs of the first two candidates, as a two-query comparison reports them
+	Query1Stats *QueryStats       `json:"query1_stats"`
+	Query2Stats *QueryStats       `json:"query2_stats"`
+	Candidates  []CandidateResult `json:"candidates"`
+}
+
+type CandidateResult struct {
+	Label          string `json:"label"`
+	Query          string `json:"query"`
+	ConnectionID   int64  `json:"connection_id"`
+	ConnectionName string `json:"connection_name"`
+	ServerInfo     string `json:"server_info"`
+	// Stats of the run with the median duration
+	Stats     *QueryStats     `json:"stats"`
+	Benchmark *BenchmarkStats `json:"benchmark"`
+	// Verdict against the baseline, unset on the baseline itself
+	Verdict *CompareVerdict `json:"verdict,omitempty"`
+	// Checksum and columns of the result when the rows were checked, SameResult tells whether both match the baseline ones
+	Checksum      *ResultChecksum     `json:"checksum,omitempty"`
+	Columns       []TableSchemaColumn `json:"columns,omitempty"`
+	ChecksumError string              `json:"checksum_error,omitempty"`
+	SameResult    *bool               `json:"same_result,omitempty"`
+	Diff          *ResultDiff         `json:"diff,omitempty"`
+}
+
+// BenchmarkStats aggregates the measured runs of a query
+type BenchmarkStats struct {
+	Iterations  int     `json:"iterations"`
+	DurationsMs []int64 `json:"durations_ms"` // in run order
+	// Runs without a query_log entry, their duration is the client side one and their other metrics are zero
+	UnavailableRuns int           `json:"unavailable_runs"`
+	ExecutionTimeMs MetricSummary `json:"execution_time_ms"`
+	RowsRead        MetricSummary `json:"rows_read"`
+	BytesRead       MetricSummary `json:"bytes_read"`
+	MemoryPeak      MetricSummary `json:"memory_peak"`
+}
+
+type MetricSummary struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+}
+
+// CompareVerdict tells whether the durations of a query really differ from the baseline ones, by a Mann-Whitney U test
+type CompareVerdict struct {
+	Significant   bool    `json:"significant"`
+	Faster        bool    `json:"faster"`         // the query beats the baseline, only meaningful when significant
+	ChangePercent float64 `json:"change_percent"` // median duration against the baseline one
+	PValue        float64 `json:"p_value"`
+	Summary       string  `json:"summary"`
+}
+
+// ResultChecksum identifies the rows of a result whatever their order
+type ResultChecksum struct {
+	Rows uint64 `json:"rows"`
+	Hash string `json:"hash"` // hex of groupBitXor and sum of cityHash64(*), UInt64 values do not survive JSON numbers in the browser
+}
+
+// ResultDiff tells how the result of a candidate differs from the baseline one
+type ResultDiff struct {
+	RowsDelta int64 `json:"rows_delta"` // candidate rows minus baseline rows
+	// Columns are matched by name, ColumnOrderDiffers when they only differ by position
+	MissingColumns     []string         `json:"missing_columns"`
+	ExtraColumns       []string         `json:"extra_columns"`
+	TypeMismatches     []ColumnMismatch `json:"type_mismatches"`
+	ColumnOrderDiffers bool             `json:"column_order_differs"`
+	// A sample of the baseline rows the candidate lacks and of the rows it adds
+	MissingRows []map[string]interface{} `json:"missing_rows"`
+	ExtraRows   []map[string]interface{} `json:"extra_rows"`
+	// Why the rows could not be sampled, when they were not
+	SampleError string `json:"sample_error,omitempty"`
+}
+
+type ColumnMismatch struct {
+	Name         string `json:"name"`
+	BaselineType string `json:"baseline_type"`
+	Type         string `json:"type"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+	HealthStatusOnline  = "Online"
+	HealthStatusOffline = "Offline"
+)
+
+// ConnectionHealth is a single health check result recorded by the background monitor
+type ConnectionHealth struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConnectionID  int64     `gorm:"index;not null" json:"connection_id"`
+	Status        string    `gorm:"type:varchar(20);not null" json:"status"`
+	LatencyMs     int64     `json:"latency_ms"`
+	ServerVersion string    `gorm:"type:varchar(100)" json:"server_version"`
+	Error         string    `gorm:"type:text" json:"error"`
+	Since         time.Time `json:"since"` // when the current status (Online/Offline) started
+	CheckedAt     time.Time `gorm:"index" json:"checked_at"`
+}
+
+// TableName overrides the table name used by ConnectionHealth to `connection_health_checks`
+func (ConnectionHealth) TableName() string {
+	return "connection_health_checks"
+}
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// ConnectionProfileVersion is bumped whenever the layout of exported profiles changes
+const ConnectionProfileVersion = 1
+
+// Outcomes of importing a single connection profile
+const (
+	ImportStatusCreated   = "created"
+	ImportStatusUpdated   = "updated"
+	ImportStatusUnchanged = "unchanged"
+	ImportStatusConflict  = "conflict"
+	ImportStatusFailed    = "failed"
+)
+
+// ConnectionProfileFile is the portable export of saved connections
+type ConnectionProfileFile struct {
+	Version     int                 `json:"version" yaml:"version"`
+	ExportedAt  time.Time           `json:"exported_at" yaml:"exported_at"`
+	WithSecrets bool                `json:"with_secrets" yaml:"with_secrets"`
+	Connections []ConnectionProfile `json:"connections" yaml:"connections"`
+}
+
+// ConnectionProfile mirrors CHConnection without local state (IDs, timestamps, server info).
+// Password is plaintext and only present when exported with secrets.
+type ConnectionProfile struct {
+	Name             string `json:"name" yaml:"name"`
+	Host             string `json:"host" yaml:"host"`
+	Port             int    `json:"port" yaml:"port"`
+	Hosts            string `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	ConnOpenStrategy string `json:"conn_open_strategy,omitempty" yaml:"conn_open_strategy,omitempty"`
+	Username         string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password         string `json:"password,omitempty" yaml:"password,omitempty"`
+	Database         string `json:"database,omitempty" yaml:"database,omitempty"`
+	Protocol         string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Label            string `json:"label,omitempty" yaml:"label,omitempty"`
+	UseSSL           bool   `json:"use_ssl" yaml:"use_ssl"`
+	TLSCACert        string `json:"tls_ca_cert,omitempty" yaml:"tls_ca_cert,omitempty"`
+	TLSClientCert    string `json:"tls_client_cert,omitempty" yaml:"tls_client_cert,omitempty"`
+	TLSClientKey     string `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty"`
+	TLSServerName    string `json:"tls_server_name,omitempty" yaml:"tls_server_name,omitempty"`
+	TLSSkipVerify    bool   `json:"tls_skip_verify,omitempty" yaml:"tls_skip_verify,omitempty"`
+	MaxOpenConns     int    `json:"max_open_conns,omitempty" yaml:"max_open_conns,omitempty"`
+	MaxIdleConns     int    `json:"max_idle_conns,omitempty" yaml:"max_idle_conns,omitempty"`
+}
+
+// ConnectionImportResult summarizes an import, one entry per profile in file order
+type ConnectionImportResult struct {
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	Conflicts int                     `json:"conflicts"`
+	Failed    int                     `json:"failed"`
+	Entries   []ConnectionImportEntry `json:"entries"`
+}
+
+type ConnectionImportEntry struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// PoolStats describes one driver pool kept by the ClickHouse client
+type PoolStats struct {
+	ConnectionID   int64     `json:"connection_id"`
+	ConnectionName string    `json:"connection_name"`
+	Endpoints      []string  `json:"endpoints"`
+	Open           int       `json:"open"`
+	Idle           int       `json:"idle"`
+	MaxOpenConns   int       `json:"max_open_conns"`
+	MaxIdleConns   int       `json:"max_idle_conns"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastUsedAt     time.Time `json:"last_used_at"`
+	AgeSeconds     int64     `json:"age_seconds"`
+	IdleSeconds    int64     `json:"idle_seconds"`
+}
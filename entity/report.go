@@ -4,10 +4,47 @@ import (
 	"time"
 )
 
+// Time windows of the slow query report, a custom one is given by From and To
+const (
+	SlowQueryRange1h     = "1h"
+	SlowQueryRange6h     = "6h"
+	SlowQueryRange24h    = "24h"
+	SlowQueryRange7d     = "7d"
+	SlowQueryRange30d    = "30d"
+	SlowQueryRangeCustom = "custom"
+)
+
+// Metrics the slow query report ranks the queries by
+const (
+	SlowQuerySortMaxDuration   = "max_duration"
+	SlowQuerySortP95Duration   = "p95_duration"
+	SlowQuerySortTotalDuration = "total_duration"
+	SlowQuerySortExecutions    = "executions"
+	SlowQuerySortBytesRead     = "bytes_read"
+	SlowQuerySortMemory        = "memory"
+)
+
+// SlowQueryFilter selects and ranks the queries of the slow query report. Empty fields take their default:
+// every query kind, the last 24 hours, 20 queries ranked by max duration and no user, database or table filter.
+type SlowQueryFilter struct {
+	QueryKind string    `json:"query_kind"`
+	Range     string    `json:"range"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Limit     int       `json:"limit"`
+	SortBy    string    `json:"sort"`
+	User      string    `json:"user"`
+	Database  string    `json:"database"`
+	Table     string    `json:"table"` // table or database.table
+}
+
 // SlowQueryReport represents a row in the Top 10 Slow Queries report
 type SlowQueryReport struct {
-	ID              int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	ConnectionID    int64     `gorm:"index" json:"connection_id"`
+	ID           int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConnectionID int64 `gorm:"index" json:"connection_id"`
+	// SnapshotKey tells the filter the row was computed for, Rank its place in that snapshot
+	SnapshotKey     string    `gorm:"index" json:"-"`
+	Rank            int       `json:"rank"`
 	QueryKind       string    `json:"query_kind"`
 	ExecutedBy      string    `json:"executed_by"`
 	SampleQuery     string    `json:"sample_query"`
@@ -16,8 +53,10 @@ type SlowQueryReport struct {
 	AvgDurationMs   float64   `json:"avg_duration_ms"`
 	P95DurationMs   float64   `json:"p95_duration_ms"`
 	MaxDurationMs   float64   `json:"max_duration_ms"`
+	TotalDurationMs float64   `json:"total_duration_ms"`
 	TotalRowsRead   uint64    `json:"total_rows_read"`
 	TotalBytesRead  uint64    `json:"total_bytes_read"`
+	PeakMemoryUsage uint64    `json:"peak_memory_usage"`
 	LastRefresh     time.Time `json:"last_refresh"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
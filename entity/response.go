@@ -11,6 +11,7 @@ const (
 	INVALID_TOKEN_MSG    = "Invalid Access Token"
 	BAD_REQUEST_CODE     = "30"
 	BAD_REQUEST_MSG      = "Bad Request"
+	CONFIRMATION_CODE    = "40"
 	DATA_NOT_FOUND_MSG   = "Data not found"
 	USER_NOT_FOUND_MSG   = "User not found"
 
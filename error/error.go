@@ -65,6 +65,15 @@ func (c CustomErrorResponse) Error() string {
 	return c.Message
 }
 
+// ErrConfirmationRequired refuses a statement the user has to confirm first, sending it again with confirm runs it
+func ErrConfirmationRequired(message string) CustomErrorResponse {
+	return CustomErrorResponse{
+		Message:  message,
+		ErrCode:  entity.CONFIRMATION_CODE,
+		HTTPCode: http.StatusPreconditionRequired,
+	}
+}
+
 func ErrGeneralInvalid() CustomErrorResponse {
 	return CustomErrorResponse{
 		Message:  entity.GENERAL_ERROR_MESSAGE,
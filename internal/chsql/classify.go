@@ -0,0 +1,60 @@
+package chsql
+
+import "strings"
+
+// Kinds of statement, everything but KindRead changes the server
+const (
+	KindRead    = "read"    // SELECT, SHOW, EXPLAIN... and USE
+	KindSession = "session" // SET, which can lift readonly or enable allow_* settings for the statements after it
+	KindWrite   = "write"   // INSERT, DELETE, UPDATE and ALTER ... DELETE/UPDATE mutations
+	KindDDL     = "ddl"     // CREATE, DROP, ALTER, TRUNCATE, RENAME, ATTACH, DETACH...
+	KindAdmin   = "admin"   // SYSTEM, KILL, OPTIMIZE, GRANT... and any statement not recognised
+)
+
+// Classify tells what a single statement does from its keywords, unknown statements are KindAdmin so they are never taken for reads
+func Classify(query string) string {
+	switch FirstKeyword(query) {
+	case "", "SELECT", "WITH", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "EXISTS", "CHECK", "USE", "WATCH":
+		return KindRead
+	case "SET":
+		return KindSession
+	case "INSERT", "DELETE", "UPDATE":
+		return KindWrite
+	case "ALTER":
+		for _, word := range keywords(query) {
+			if word == "DELETE" || word == "UPDATE" {
+				return KindWrite
+			}
+		}
+		return KindDDL
+	case "CREATE", "DROP", "TRUNCATE", "RENAME", "ATTACH", "DETACH", "EXCHANGE", "UNDROP":
+		return KindDDL
+	default:
+		return KindAdmin
+	}
+}
+
+// keywords returns the upper-cased words of a statement, leaving out literals, quoted identifiers and comments
+func keywords(query string) []string {
+	var words []string
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			i = skipLineComment(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case isWordChar(c):
+			end := i
+			for end < len(query) && isWordChar(query[end]) {
+				end++
+			}
+			words = append(words, strings.ToUpper(query[i:end]))
+			i = end
+		default:
+			i++
+		}
+	}
+	return words
+}
@@ -0,0 +1,30 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := map[string]string{
+		"-- report\nSELECT * FROM t":                 chsql.KindRead,
+		"EXPLAIN indexes = 1 SELECT 1":               chsql.KindRead,
+		"SET max_threads = 4":                        chsql.KindSession,
+		"set readonly = 0":                           chsql.KindSession,
+		"INSERT INTO t SELECT * FROM s":              chsql.KindWrite,
+		"alter table t DELETE WHERE id = 1":          chsql.KindWrite,
+		"ALTER TABLE t UPDATE n = 0 WHERE 1":         chsql.KindWrite,
+		"ALTER TABLE t ADD COLUMN `delete` UInt8":    chsql.KindDDL,
+		"/* cleanup */ DROP TABLE IF EXISTS t":       chsql.KindDDL,
+		"TRUNCATE TABLE t":                           chsql.KindDDL,
+		"SYSTEM DROP MARK CACHE":                     chsql.KindAdmin,
+		"OPTIMIZE TABLE t FINAL":                     chsql.KindAdmin,
+		"KILL QUERY WHERE query_id = 'delete' ASYNC": chsql.KindAdmin,
+	}
+
+	for query, want := range tests {
+		assert.Equal(t, want, chsql.Classify(query), query)
+	}
+}
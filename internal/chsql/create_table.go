@@ -0,0 +1,213 @@
+package chsql
+
+import "strings"
+
+// ColumnDefinition is a column declared by CREATE TABLE
+type ColumnDefinition struct {
+	Name string
+	Type string
+}
+
+// TableDefinition is what a CREATE TABLE statement declares, expressions are kept as written
+type TableDefinition struct {
+	Engine      string // without its arguments
+	Columns     []ColumnDefinition
+	OrderBy     []string // the sorting key, one expression per element
+	PrimaryKey  []string // the ORDER BY key unless PRIMARY KEY is given
+	PartitionBy string
+}
+
+// Words that end a column type, the column options follow
+var columnOptionWords = toSet(`
+	DEFAULT MATERIALIZED ALIAS EPHEMERAL CODEC TTL COMMENT NULL NOT PRIMARY STATISTICS SETTINGS
+`)
+
+// Words that start a table clause after the engine
+var tableClauseWords = toSet(`
+	ENGINE ORDER PARTITION PRIMARY SAMPLE TTL SETTINGS COMMENT AS SELECT EMPTY
+`)
+
+// ParseCreateTable reads the engine, columns and keys of a CREATE TABLE statement, as SHOW CREATE TABLE prints it.
+// What it cannot read is left empty.
+func ParseCreateTable(sql string) TableDefinition {
+	d := defParser{sql: sql, tokens: Tokenize(sql)}
+	var def TableDefinition
+
+	i := d.columnList()
+	if i > 0 {
+		def.Columns, def.PrimaryKey = d.columns(i)
+		i = d.closing(i) + 1
+	}
+
+	for i < len(d.tokens) {
+		t := d.tokens[i]
+		switch {
+		case t.Is("ENGINE"):
+			i++
+			if d.symbolAt(i, "=") {
+				i++
+			}
+			if i < len(d.tokens) && d.tokens[i].IsName() {
+				def.Engine = d.tokens[i].Text
+			}
+			i = d.skipExpression(i)
+		case t.Is("ORDER") && d.wordAt(i+1, "BY"):
+			def.OrderBy = d.keyList(i+2, d.skipExpression(i+2))
+			i = d.skipExpression(i + 2)
+		case t.Is("PRIMARY") && d.wordAt(i+1, "KEY"):
+			def.PrimaryKey = d.keyList(i+2, d.skipExpression(i+2))
+			i = d.skipExpression(i + 2)
+		case t.Is("PARTITION") && d.wordAt(i+1, "BY"):
+			def.PartitionBy = d.text(i+2, d.skipExpression(i+2))
+			i = d.skipExpression(i + 2)
+		case t.Is("AS") || t.Is("SELECT"):
+			// CREATE TABLE ... AS SELECT, the rest is a query
+			i = len(d.tokens)
+		case t.Kind == TokenSymbol && t.Text == "(":
+			i = d.closing(i) + 1
+		default:
+			i++
+		}
+	}
+
+	if def.PrimaryKey == nil {
+		def.PrimaryKey = def.OrderBy
+	}
+	return def
+}
+
+type defParser struct {
+	sql    string
+	tokens []Token
+}
+
+func (d *defParser) symbolAt(i int, symbol string) bool {
+	return i < len(d.tokens) && d.tokens[i].Kind == TokenSymbol && d.tokens[i].Text == symbol
+}
+
+func (d *defParser) wordAt(i int, word string) bool {
+	return i < len(d.tokens) && d.tokens[i].Is(word)
+}
+
+// columnList returns the index of the parenthesis opening the column list, 0 when there is none
+func (d *defParser) columnList() int {
+	for i, t := range d.tokens {
+		if t.Kind == TokenWord && tableClauseWords[strings.ToUpper(t.Text)] {
+			return 0
+		}
+		if t.Kind == TokenSymbol && t.Text == "(" {
+			return i
+		}
+	}
+	return 0
+}
+
+// closing returns the index of the parenthesis closing the one opened at i
+func (d *defParser) closing(i int) int {
+	depth := 0
+	for ; i < len(d.tokens); i++ {
+		if d.symbolAt(i, "(") {
+			depth++
+		} else if d.symbolAt(i, ")") {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// skipExpression returns the index of the table clause following the expression at i
+func (d *defParser) skipExpression(i int) int {
+	for i < len(d.tokens) {
+		t := d.tokens[i]
+		if t.Kind == TokenWord && tableClauseWords[strings.ToUpper(t.Text)] {
+			return i
+		}
+		if t.Kind == TokenSymbol && t.Text == "(" {
+			i = d.closing(i)
+		}
+		i++
+	}
+	return i
+}
+
+// text returns the query text from token i up to token end
+func (d *defParser) text(i, end int) string {
+	if i >= end || i >= len(d.tokens) {
+		return ""
+	}
+	stop := len(d.sql)
+	if end < len(d.tokens) {
+		stop = d.tokens[end].Pos
+	}
+	return strings.TrimSpace(d.sql[d.tokens[i].Pos:stop])
+}
+
+// split cuts the tokens from i up to end at the commas outside parentheses
+func (d *defParser) split(i, end int) [][2]int {
+	var parts [][2]int
+	start := i
+	for ; i < end; i++ {
+		if d.symbolAt(i, "(") {
+			i = d.closing(i)
+		} else if d.symbolAt(i, ",") {
+			parts = append(parts, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	if start < end {
+		parts = append(parts, [2]int{start, end})
+	}
+	return parts
+}
+
+// keyList reads a key expression: a tuple lists one expression per element, tuple() is an empty key
+func (d *defParser) keyList(i, end int) []string {
+	if i >= end {
+		return nil
+	}
+	if d.wordAt(i, "tuple") && d.symbolAt(i+1, "(") && d.closing(i+1) == end-1 {
+		i++
+	}
+	if d.symbolAt(i, "(") && d.closing(i) == end-1 {
+		keys := []string{}
+		for _, part := range d.split(i+1, end-1) {
+			keys = append(keys, d.text(part[0], part[1]))
+		}
+		return keys
+	}
+	return []string{d.text(i, end)}
+}
+
+// columns reads the column list opened at i and the PRIMARY KEY it may declare
+func (d *defParser) columns(i int) ([]ColumnDefinition, []string) {
+	var columns []ColumnDefinition
+	var primaryKey []string
+	for _, part := range d.split(i+1, d.closing(i)) {
+		start, end := part[0], part[1]
+		first := d.tokens[start]
+		switch {
+		case first.Is("PRIMARY") && d.wordAt(start+1, "KEY"):
+			primaryKey = d.keyList(start+2, end)
+			continue
+		case first.Is("INDEX") || first.Is("PROJECTION") || first.Is("CONSTRAINT") || !first.IsName():
+			continue
+		}
+
+		typeEnd := start + 1
+		for typeEnd < end {
+			t := d.tokens[typeEnd]
+			if t.Kind == TokenWord && columnOptionWords[strings.ToUpper(t.Text)] {
+				break
+			}
+			if t.Kind == TokenSymbol && t.Text == "(" {
+				typeEnd = d.closing(typeEnd)
+			}
+			typeEnd++
+		}
+		columns = append(columns, ColumnDefinition{Name: first.Text, Type: d.text(start+1, typeEnd)})
+	}
+	return columns, primaryKey
+}
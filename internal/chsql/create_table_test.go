@@ -0,0 +1,53 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCreateTable(t *testing.T) {
+	def := chsql.ParseCreateTable(`CREATE TABLE analytics.events
+(
+    ` + "`tenant_id`" + ` UInt32,
+    ` + "`ts`" + ` DateTime64(3, 'UTC') CODEC(Delta, ZSTD),
+    ` + "`country`" + ` LowCardinality(String),
+    ` + "`referrer`" + ` Nullable(String) DEFAULT NULL COMMENT 'first touch',
+    ` + "`status`" + ` Enum8('ok' = 1, 'failed, retried' = 2),
+    INDEX idx_referrer referrer TYPE bloom_filter GRANULARITY 4,
+    PROJECTION by_country (SELECT country, count() GROUP BY country ORDER BY country)
+)
+ENGINE = ReplicatedMergeTree('/clickhouse/tables/{shard}/events', '{replica}')
+PARTITION BY toYYYYMM(ts)
+ORDER BY (tenant_id, toStartOfHour(ts), country)
+SETTINGS index_granularity = 8192`)
+
+	assert.Equal(t, "ReplicatedMergeTree", def.Engine)
+	assert.Equal(t, []chsql.ColumnDefinition{
+		{Name: "tenant_id", Type: "UInt32"},
+		{Name: "ts", Type: "DateTime64(3, 'UTC')"},
+		{Name: "country", Type: "LowCardinality(String)"},
+		{Name: "referrer", Type: "Nullable(String)"},
+		{Name: "status", Type: "Enum8('ok' = 1, 'failed, retried' = 2)"},
+	}, def.Columns)
+	assert.Equal(t, "toYYYYMM(ts)", def.PartitionBy)
+	assert.Equal(t, []string{"tenant_id", "toStartOfHour(ts)", "country"}, def.OrderBy)
+	assert.Equal(t, def.OrderBy, def.PrimaryKey)
+}
+
+func TestParseCreateTableKeys(t *testing.T) {
+	def := chsql.ParseCreateTable("CREATE TABLE t (id UInt64, ts DateTime) ENGINE = MergeTree PRIMARY KEY id ORDER BY (id, ts)")
+	assert.Equal(t, []string{"id", "ts"}, def.OrderBy)
+	assert.Equal(t, []string{"id"}, def.PrimaryKey)
+
+	def = chsql.ParseCreateTable("CREATE TABLE t (id UInt64, PRIMARY KEY (id)) ENGINE = MergeTree ORDER BY tuple()")
+	assert.Equal(t, []string{}, def.OrderBy)
+	assert.Equal(t, []string{"id"}, def.PrimaryKey)
+
+	def = chsql.ParseCreateTable("CREATE TABLE t (x String) ENGINE = Memory")
+	assert.Equal(t, "Memory", def.Engine)
+	assert.Nil(t, def.OrderBy)
+
+	assert.Equal(t, chsql.TableDefinition{}, chsql.ParseCreateTable("-- Failed to fetch CREATE TABLE for db.t: timeout"))
+}
@@ -0,0 +1,38 @@
+package chsql
+
+import "strings"
+
+// FirstKeyword returns the upper-cased first word of a statement, after comments and opening parentheses
+func FirstKeyword(query string) string {
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			i = skipLineComment(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(':
+			i++
+		default:
+			end := i
+			for end < len(query) && isWordChar(query[end]) {
+				end++
+			}
+			return strings.ToUpper(query[i:end])
+		}
+	}
+	return ""
+}
+
+// ReturnsRows reports whether the statement produces a result set, the others are executed without reading one
+func ReturnsRows(query string) bool {
+	switch FirstKeyword(query) {
+	case "SELECT", "WITH", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "EXISTS", "CHECK":
+		return true
+	default:
+		return false
+	}
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,72 @@
+package chsql
+
+import "strings"
+
+// Parameter is a {name:Type} placeholder, bound server side through the query parameters
+type Parameter struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Parameters lists the placeholders of a query or script in order of first use, the ones inside
+// string literals and comments are ignored. A name used twice is listed once, with its first type.
+func Parameters(query string) []Parameter {
+	var (
+		params []Parameter
+		seen   = map[string]bool{}
+	)
+
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			i = skipLineComment(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '$':
+			end, ok := skipHeredoc(query, i)
+			if !ok {
+				end = i + 1
+			}
+			i = end
+		case c == '{':
+			param, end, ok := parsePlaceholder(query, i)
+			if !ok {
+				i++
+				continue
+			}
+			if !seen[param.Name] {
+				seen[param.Name] = true
+				params = append(params, param)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+
+	return params
+}
+
+// parsePlaceholder reads {name:Type} at i, types never hold braces so the first closing one ends it
+func parsePlaceholder(s string, i int) (Parameter, int, bool) {
+	end := strings.IndexByte(s[i:], '}')
+	if end < 0 {
+		return Parameter{}, 0, false
+	}
+	body := s[i+1 : i+end]
+
+	name, typ, ok := strings.Cut(body, ":")
+	name, typ = strings.TrimSpace(name), strings.TrimSpace(typ)
+	if !ok || name == "" || typ == "" || strings.ContainsAny(body, "{\n") {
+		return Parameter{}, 0, false
+	}
+	for j := 0; j < len(name); j++ {
+		if !isWordChar(name[j]) || j == 0 && name[j] >= '0' && name[j] <= '9' {
+			return Parameter{}, 0, false
+		}
+	}
+
+	return Parameter{Name: name, Type: typ}, i + end + 1, true
+}
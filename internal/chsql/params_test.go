@@ -0,0 +1,23 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameters(t *testing.T) {
+	query := `SELECT * FROM events
+WHERE date >= {from:Date} AND user_id IN {ids: Array(UInt64)}
+	AND note != '{not:String}' -- {nor:String}
+	AND date < {from:Date} + 7 AND tags = {m:Map(String, UInt8)} AND x = {1bad:UInt8}`
+
+	assert.Equal(t, []chsql.Parameter{
+		{Name: "from", Type: "Date"},
+		{Name: "ids", Type: "Array(UInt64)"},
+		{Name: "m", Type: "Map(String, UInt8)"},
+	}, chsql.Parameters(query))
+
+	assert.Empty(t, chsql.Parameters("SELECT {}, map('a', 1)"))
+}
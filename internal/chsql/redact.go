@@ -0,0 +1,80 @@
+package chsql
+
+import "strings"
+
+// Redact replaces the string and number literals of a query with ?, the way normalizeQuery does, and drops its
+// comments. Everything else keeps its place, so the query reads the same without the values it was run with.
+func Redact(query string) string {
+	var sb strings.Builder
+	last := 0
+	for _, t := range Tokenize(query) {
+		sb.WriteString(stripComments(query[last:t.Pos]))
+
+		end := tokenEnd(query, t)
+		if t.Kind == TokenString || t.Kind == TokenNumber {
+			sb.WriteString("?")
+		} else {
+			sb.WriteString(query[t.Pos:end])
+		}
+		last = end
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Normalize returns the redacted query on one line, its tokens separated by a single space and a list of
+// literals collapsed into ?.., so queries that only differ in their values, layout or comments are equal
+func Normalize(query string) string {
+	var parts []string
+	for _, t := range Tokenize(query) {
+		text := query[t.Pos:tokenEnd(query, t)]
+		if t.Kind == TokenString || t.Kind == TokenNumber {
+			n := len(parts)
+			if n >= 2 && parts[n-1] == "," && (parts[n-2] == "?" || parts[n-2] == "?..") {
+				parts = parts[:n-1]
+				parts[n-2] = "?.."
+				continue
+			}
+			text = "?"
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// tokenEnd returns the index after the token in the query, literals and quoted identifiers hold their text unquoted
+func tokenEnd(query string, t Token) int {
+	switch {
+	case t.Kind == TokenIdent, t.Kind == TokenString && query[t.Pos] == '\'':
+		return skipQuoted(query, t.Pos, query[t.Pos])
+	case t.Kind == TokenString:
+		end, _ := skipHeredoc(query, t.Pos)
+		return end
+	default:
+		return t.Pos + len(t.Text)
+	}
+}
+
+// stripComments returns the whitespace between two tokens without its comments, from the last line break on
+func stripComments(gap string) string {
+	if strings.TrimSpace(gap) == "" {
+		return gap
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(gap); {
+		switch c := gap[i]; {
+		case c == '-' && strings.HasPrefix(gap[i:], "--"), c == '#':
+			i = skipLineComment(gap, i)
+		case c == '/' && strings.HasPrefix(gap[i:], "/*"):
+			i = skipBlockComment(gap, i)
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	space := sb.String()
+	if nl := strings.LastIndexByte(space, '\n'); nl >= 0 {
+		return space[nl:]
+	}
+	return " "
+}
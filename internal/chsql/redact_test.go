@@ -0,0 +1,30 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	query := `SELECT user_id, 'it''s' AS label -- secret note
+FROM "events"
+WHERE email = 'a@b.c' AND amount > 10.5e3 AND body = $x$raw$x$ /* customer
+42 */
+  AND tenant_id = {tenant:UInt32}`
+
+	assert.Equal(t, `SELECT user_id, ? AS label
+FROM "events"
+WHERE email = ? AND amount > ? AND body = ?
+  AND tenant_id = {tenant:UInt32}`, chsql.Redact(query))
+}
+
+func TestNormalize(t *testing.T) {
+	a := chsql.Normalize("SELECT * FROM t WHERE id IN (1, 2, 3) AND name = 'x' -- first")
+	b := chsql.Normalize("SELECT *\nFROM t\nWHERE id IN (7, 8)\n  AND name = 'y'")
+
+	assert.Equal(t, "SELECT * FROM t WHERE id IN ( ?.. ) AND name = ?", a)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, chsql.Normalize("SELECT * FROM t WHERE id IN (1)"))
+}
@@ -0,0 +1,423 @@
+package chsql
+
+import "strings"
+
+// TableRef is a table a query reads or writes
+type TableRef struct {
+	Database string `json:"database,omitempty"` // empty when the query relies on the current database
+	Table    string `json:"table"`
+	Alias    string `json:"alias,omitempty"`
+}
+
+// ColumnRef is a column a query uses, Table is the table it is qualified with, its alias resolved
+type ColumnRef struct {
+	Table string `json:"table,omitempty"`
+	Name  string `json:"name"`
+}
+
+// References lists what a query refers to, each in order of first use
+type References struct {
+	Tables         []TableRef  `json:"tables"`
+	TableFunctions []string    `json:"table_functions"`
+	Dictionaries   []string    `json:"dictionaries"`
+	Columns        []ColumnRef `json:"columns"`
+}
+
+// Words that are never the name of a table alias or a column.
+// Interval units and type names are left out, they are common column names and are told apart by position.
+var reservedWords = toSet(`
+	ADD ALL ALTER AND ANTI ANY ARRAY AS ASC ASOF BETWEEN BOTH BY CASE CLUSTER COLLATE CREATE CROSS CUBE
+	DEDUPLICATE DELETE DESC DESCENDING ASCENDING DISTINCT DROP ELSE END ENGINE EXCEPT EXISTS FETCH FILL FINAL FIRST FOLLOWING
+	FOR FORMAT FROM FULL FUNCTION GLOBAL GROUP HAVING IF ILIKE IN INNER INSERT INTERSECT INTERVAL INTO IS
+	JOIN LAST LEADING LEFT LIKE LIMIT LOCAL MODIFY NATURAL NEXT NOT NULL NULLS OFFSET ON ONLY OPTIMIZE OR
+	ORDER OUTER OVER PARTITION PASTE PRECEDING PREWHERE QUALIFY RANGE RIGHT ROLLUP ROW ROWS SAMPLE SELECT
+	SEMI SET SETTINGS STEP TABLE THEN TIES TO TOTALS TRAILING TRUNCATE UNBOUNDED UNION UPDATE USING VALUES
+	WHEN WHERE WINDOW WITH CURRENT TRUE FALSE
+`)
+
+// Keywords that end the table list of a FROM clause
+var clauseWords = toSet(`
+	WHERE PREWHERE GROUP ORDER HAVING LIMIT OFFSET ON USING UNION EXCEPT INTERSECT SELECT WINDOW QUALIFY
+	ARRAY FORMAT SETTINGS INTO VALUES INNER LEFT RIGHT FULL CROSS GLOBAL ANY ALL ASOF SEMI ANTI PASTE JOIN
+`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// frame is a level of parentheses
+type frame struct {
+	query    bool // holds a query, the outermost level does
+	fromList bool // in a FROM clause, a comma starts another table
+	settings bool // in a SETTINGS clause, its names are not columns
+	subquery bool // a subquery used as a table, an alias can follow
+	position bool // the arguments of position(needle IN haystack), IN does not name a table there
+}
+
+type columnUse struct {
+	qualifier []string
+	name      string
+}
+
+type refParser struct {
+	tokens []Token
+	stack  []frame
+
+	ctes         map[string]bool
+	lambdas      map[string]bool
+	aliases      map[string]bool
+	tableAliases map[string]string // alias or bare table name, to the table as written
+	columns      []columnUse
+
+	refs References
+}
+
+// ExtractReferences resolves the tables, table functions, dictionaries and columns a query refers to.
+// Names of CTEs, aliases and lambda parameters are not reported, nor are the tables inside table functions.
+func ExtractReferences(query string) References {
+	p := &refParser{
+		tokens:       Tokenize(query),
+		stack:        []frame{{query: true}},
+		ctes:         make(map[string]bool),
+		lambdas:      make(map[string]bool),
+		aliases:      make(map[string]bool),
+		tableAliases: make(map[string]string),
+	}
+	p.prescan()
+
+	for i := 0; i < len(p.tokens); {
+		i = p.step(i)
+	}
+
+	p.resolveColumns()
+	return p.refs
+}
+
+func (p *refParser) top() *frame {
+	return &p.stack[len(p.stack)-1]
+}
+
+func (p *refParser) at(i int) Token {
+	if i < 0 || i >= len(p.tokens) {
+		return Token{}
+	}
+	return p.tokens[i]
+}
+
+func (p *refParser) symbolAt(i int, symbol string) bool {
+	t := p.at(i)
+	return t.Kind == TokenSymbol && t.Text == symbol
+}
+
+// opensQuery reports whether the parenthesis at i holds a query
+func (p *refParser) opensQuery(i int) bool {
+	return p.at(i+1).Is("SELECT") || p.at(i+1).Is("WITH")
+}
+
+// prescan finds the names a query defines before using them: CTEs and lambda parameters
+func (p *refParser) prescan() {
+	for i, t := range p.tokens {
+		switch {
+		case t.Is("AS") && p.symbolAt(i+1, "(") && p.opensQuery(i+1) && p.at(i-1).IsName():
+			p.ctes[p.at(i-1).Text] = true
+		case t.Kind == TokenSymbol && t.Text == "->":
+			if p.at(i - 1).IsName() {
+				p.lambdas[p.at(i-1).Text] = true
+				continue
+			}
+			// (x, y) -> ...
+			for j := i - 2; j >= 0 && !p.symbolAt(j, "("); j-- {
+				if p.at(j).IsName() {
+					p.lambdas[p.at(j).Text] = true
+				}
+			}
+		}
+	}
+}
+
+func (p *refParser) step(i int) int {
+	t := p.tokens[i]
+	top := p.top()
+
+	switch {
+	case t.Kind == TokenSymbol && t.Text == "(":
+		p.stack = append(p.stack, frame{query: p.opensQuery(i), position: p.at(i - 1).Is("POSITION")})
+		return i + 1
+	case t.Kind == TokenSymbol && t.Text == ")":
+		if len(p.stack) == 1 {
+			return i + 1
+		}
+		closed := *top
+		p.stack = p.stack[:len(p.stack)-1]
+		if closed.subquery {
+			next, _ := p.alias(i+1, "")
+			return next
+		}
+		return i + 1
+	case t.Kind == TokenSymbol && t.Text == ";":
+		p.stack = []frame{{query: true}}
+		return i + 1
+	case t.Kind == TokenSymbol && t.Text == "," && top.query && top.fromList:
+		return p.table(i+1, false)
+	case t.Kind != TokenWord:
+		if t.Kind == TokenIdent {
+			return p.name(i)
+		}
+		return i + 1
+	}
+
+	word := strings.ToUpper(t.Text)
+	if word == "AS" && p.at(i+1).IsName() {
+		if p.symbolAt(i+2, "(") {
+			// CAST(x AS Nullable(String))
+			return p.skipParens(i + 2)
+		}
+		p.aliases[p.at(i+1).Text] = true
+		return i + 2
+	}
+	if word == "IN" && !top.position && p.inTable(i+1) {
+		// a [GLOBAL] IN t reads the set from a table
+		return p.table(i+1, false)
+	}
+	if !top.query {
+		if word == "FROM" {
+			return i + 1
+		}
+		return p.name(i)
+	}
+
+	switch {
+	case word == "FROM":
+		top.fromList, top.settings = true, false
+		return p.table(i+1, false)
+	case word == "JOIN" && !p.at(i-1).Is("ARRAY"):
+		// The ON and USING clauses of a join do not list tables
+		top.fromList, top.settings = false, false
+		return p.table(i+1, false)
+	case word == "INTO" && p.at(i-1).Is("INSERT"):
+		i++
+		if p.at(i).Is("TABLE") {
+			i++
+		}
+		if p.at(i).Is("FUNCTION") {
+			return p.table(i+1, false)
+		}
+		// INSERT INTO t (a, b): the parenthesis lists columns of the table
+		return p.table(i, true)
+	case word == "TABLE" && !p.at(i-1).Is("INTO"):
+		return p.table(i+1, true)
+	case i == 0 && (word == "DESCRIBE" || word == "DESC" || word == "UPDATE") && !p.at(1).Is("TABLE"):
+		return p.table(i+1, false)
+	case word == "SETTINGS":
+		top.fromList, top.settings = false, true
+		return i + 1
+	case word == "FORMAT":
+		top.fromList = false
+		return i + 2
+	case clauseWords[word]:
+		top.fromList, top.settings = false, false
+		return i + 1
+	}
+	return p.name(i)
+}
+
+// inTable reports whether IN is followed by a bare table name at i, rather than a tuple, an array, a function or a
+// set defined by WITH
+func (p *refParser) inTable(i int) bool {
+	t := p.at(i)
+	if !t.IsName() || t.Kind == TokenWord && reservedWords[strings.ToUpper(t.Text)] {
+		return false
+	}
+	parts, next := p.qualified(i)
+	if len(parts) == 1 && (p.aliases[t.Text] || p.lambdas[t.Text]) {
+		return false
+	}
+	return !p.symbolAt(next, "(") && !p.symbolAt(next, ".")
+}
+
+// qualified reads a dotted name at i, returning its parts and the index after it
+func (p *refParser) qualified(i int) ([]string, int) {
+	parts := []string{p.at(i).Text}
+	i++
+	for p.symbolAt(i, ".") && p.at(i+1).IsName() {
+		parts = append(parts, p.at(i+1).Text)
+		i += 2
+	}
+	return parts, i
+}
+
+// table reads what follows FROM, JOIN or INTO: a table, a table function or a subquery, and its alias.
+// With columnList a parenthesis after the name lists columns, it never calls a table function.
+func (p *refParser) table(i int, columnList bool) int {
+	// TABLE IF [NOT] EXISTS name
+	if p.at(i).Is("IF") {
+		i++
+		if p.at(i).Is("NOT") {
+			i++
+		}
+		if p.at(i).Is("EXISTS") {
+			i++
+		}
+	}
+
+	t := p.at(i)
+	switch {
+	case t.Kind == TokenSymbol && t.Text == "(":
+		p.stack = append(p.stack, frame{query: p.opensQuery(i), subquery: true})
+		return i + 1
+	case t.Kind == TokenParam:
+		next, _ := p.alias(i+1, "")
+		return next
+	case !t.IsName() || t.Kind == TokenWord && reservedWords[strings.ToUpper(t.Text)]:
+		return i
+	}
+
+	parts, next := p.qualified(i)
+	name := strings.Join(parts, ".")
+
+	if p.symbolAt(next, "(") && !columnList {
+		p.refs.TableFunctions = appendOnce(p.refs.TableFunctions, name)
+		if strings.EqualFold(name, "dictionary") && p.at(next+1).Kind == TokenString {
+			p.refs.Dictionaries = appendOnce(p.refs.Dictionaries, p.at(next+1).Text)
+		}
+		if p.opensQuery(next) {
+			// view(SELECT ...) reads like a subquery
+			p.stack = append(p.stack, frame{query: true, subquery: true})
+			return next + 1
+		}
+		// The arguments name hosts, files and remote tables, none of them local
+		next, _ = p.alias(p.skipParens(next), "")
+		return next
+	}
+
+	if len(parts) == 1 && p.ctes[name] {
+		p.tableAliases[name] = name
+		next, _ = p.alias(next, name)
+		return next
+	}
+
+	ref := TableRef{Table: parts[len(parts)-1]}
+	if len(parts) > 1 {
+		ref.Database = strings.Join(parts[:len(parts)-1], ".")
+	}
+	next, ref.Alias = p.alias(next, name)
+	p.tableAliases[ref.Table] = name
+	p.addTable(ref)
+	return next
+}
+
+// alias reads the alias following a table at i, if any, and maps it to the table
+func (p *refParser) alias(i int, table string) (int, string) {
+	t := p.at(i)
+	if t.Is("AS") {
+		i++
+		t = p.at(i)
+	}
+	if !t.IsName() || t.Kind == TokenWord && reservedWords[strings.ToUpper(t.Text)] {
+		return i, ""
+	}
+	if table != "" {
+		p.tableAliases[t.Text] = table
+	}
+	p.aliases[t.Text] = true
+	return i + 1, t.Text
+}
+
+// skipParens returns the index after the parenthesis opened at i
+func (p *refParser) skipParens(i int) int {
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		if p.symbolAt(i, "(") {
+			depth++
+		} else if p.symbolAt(i, ")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// name reads a name used in an expression: a function, a column or one of the words around them
+func (p *refParser) name(i int) int {
+	t := p.at(i)
+	parts, next := p.qualified(i)
+
+	// t.* and tuple elements (t.1)
+	if p.symbolAt(next, ".") {
+		next++
+		if p.at(next).Kind == TokenNumber || p.symbolAt(next, "*") {
+			next++
+		}
+	}
+
+	switch {
+	case p.symbolAt(next, "("):
+		// A function, dictGet('db.dict', ...) and its variants name the dictionary they read
+		if len(parts) == 1 && len(t.Text) > 4 && strings.EqualFold(t.Text[:4], "dict") && p.at(next+1).Kind == TokenString {
+			p.refs.Dictionaries = appendOnce(p.refs.Dictionaries, p.at(next+1).Text)
+		}
+		return next
+	case t.Kind == TokenWord && len(parts) == 1 && reservedWords[strings.ToUpper(t.Text)]:
+		return next
+	case p.top().settings:
+		return next
+	case p.symbolAt(i-1, "::") || p.at(i-1).Is("OVER"):
+		// A type name or a window
+		return next
+	case t.Kind == TokenWord && p.at(next).Kind == TokenString:
+		// DATE '2024-01-01'
+		return next
+	case p.at(i-2).Is("INTERVAL") || p.symbolAt(i-1, "(") && p.at(i-2).Is("EXTRACT") && p.at(next).Is("FROM"):
+		// INTERVAL 1 DAY, EXTRACT(DAY FROM ...), the name before FROM in substring(s FROM 2) is a column
+		return next
+	}
+
+	p.columns = append(p.columns, columnUse{qualifier: parts[:len(parts)-1], name: parts[len(parts)-1]})
+	return next
+}
+
+// resolveColumns keeps the names that are columns once every alias is known, and resolves their table
+func (p *refParser) resolveColumns() {
+	seen := make(map[ColumnRef]bool)
+	for _, use := range p.columns {
+		if len(use.qualifier) == 0 && (p.aliases[use.name] || p.ctes[use.name] || p.lambdas[use.name] || p.tableAliases[use.name] != "") {
+			continue
+		}
+
+		ref := ColumnRef{Name: use.name}
+		if n := len(use.qualifier); n > 0 {
+			ref.Table = strings.Join(use.qualifier, ".")
+			if table, ok := p.tableAliases[use.qualifier[n-1]]; ok {
+				ref.Table = table
+			}
+		}
+		if !seen[ref] {
+			seen[ref] = true
+			p.refs.Columns = append(p.refs.Columns, ref)
+		}
+	}
+}
+
+func (p *refParser) addTable(ref TableRef) {
+	for _, existing := range p.refs.Tables {
+		if existing.Database == ref.Database && existing.Table == ref.Table {
+			return
+		}
+	}
+	p.refs.Tables = append(p.refs.Tables, ref)
+}
+
+func appendOnce(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
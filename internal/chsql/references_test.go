@@ -0,0 +1,175 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractReferencesTables(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []chsql.TableRef
+	}{
+		{
+			name:  "plain select",
+			query: "SELECT count() FROM events",
+			want:  []chsql.TableRef{{Table: "events"}},
+		},
+		{
+			name:  "database, aliases and joins",
+			query: "SELECT e.user_id, u.name FROM analytics.events AS e INNER JOIN users u ON e.user_id = u.id LEFT ANY JOIN db.sessions s USING (user_id)",
+			want: []chsql.TableRef{
+				{Database: "analytics", Table: "events", Alias: "e"},
+				{Table: "users", Alias: "u"},
+				{Database: "db", Table: "sessions", Alias: "s"},
+			},
+		},
+		{
+			name:  "quoted identifiers",
+			query: "SELECT * FROM `my db`.\"my table\" FINAL WHERE 1",
+			want:  []chsql.TableRef{{Database: "my db", Table: "my table"}},
+		},
+		{
+			name:  "comma joins",
+			query: "SELECT * FROM a, b.c AS x, d WHERE a.id = x.id",
+			want:  []chsql.TableRef{{Table: "a"}, {Database: "b", Table: "c", Alias: "x"}, {Table: "d"}},
+		},
+		{
+			name: "CTEs are not tables",
+			query: `WITH recent AS (SELECT * FROM logs.requests WHERE ts > now() - INTERVAL 1 DAY),
+				totals AS (SELECT host, count() AS hits FROM recent GROUP BY host)
+				SELECT * FROM totals JOIN hosts ON totals.host = hosts.name`,
+			want: []chsql.TableRef{{Database: "logs", Table: "requests"}, {Table: "hosts"}},
+		},
+		{
+			name:  "subqueries and IN",
+			query: "SELECT * FROM (SELECT id FROM t1 WHERE id IN (SELECT id FROM t2)) AS sub WHERE id NOT IN (SELECT id FROM db.t3)",
+			want:  []chsql.TableRef{{Table: "t1"}, {Table: "t2"}, {Database: "db", Table: "t3"}},
+		},
+		{
+			name:  "EXTRACT, TRIM and SUBSTRING are not FROM clauses",
+			query: "SELECT EXTRACT(YEAR FROM created_at), trim(BOTH ' ' FROM name), substring(s FROM 2) FROM orders",
+			want:  []chsql.TableRef{{Table: "orders"}},
+		},
+		{
+			name:  "IN reads a table",
+			query: "SELECT * FROM t1 WHERE a IN t2 AND b GLOBAL NOT IN db.t3 AND c IN (1, 2) AND d IN tuple(1) AND position('x' IN s) > 0",
+			want:  []chsql.TableRef{{Table: "t1"}, {Table: "t2"}, {Database: "db", Table: "t3"}},
+		},
+		{
+			name:  "ARRAY JOIN is not a table",
+			query: "SELECT tag FROM articles ARRAY JOIN tags AS tag LEFT ARRAY JOIN links",
+			want:  []chsql.TableRef{{Table: "articles"}},
+		},
+		{
+			name:  "table functions",
+			query: "SELECT * FROM numbers(10) AS n JOIN remote('host:9000', db.t) r ON n.number = r.id JOIN s3('https://bucket/x.parquet') USING (id)",
+			want:  nil,
+		},
+		{
+			name:  "comments and strings",
+			query: "-- FROM fake\nSELECT 'FROM also_fake', /* JOIN nope */ x FROM real_table # FROM gone",
+			want:  []chsql.TableRef{{Table: "real_table"}},
+		},
+		{
+			name:  "insert select",
+			query: "INSERT INTO TABLE archive.events (id, ts) SELECT id, ts FROM events WHERE ts < {cutoff:DateTime}",
+			want:  []chsql.TableRef{{Database: "archive", Table: "events"}, {Table: "events"}},
+		},
+		{
+			name:  "unions with settings and format",
+			query: "SELECT a FROM t1 UNION ALL SELECT a FROM t2 SETTINGS max_threads = 4, optimize_read_in_order = 1 FORMAT JSONEachRow",
+			want:  []chsql.TableRef{{Table: "t1"}, {Table: "t2"}},
+		},
+		{
+			name:  "alter and describe",
+			query: "ALTER TABLE IF EXISTS db.events DELETE WHERE id = 1",
+			want:  []chsql.TableRef{{Database: "db", Table: "events"}},
+		},
+		{
+			name:  "create table",
+			query: "CREATE TABLE IF NOT EXISTS db.t (id UInt64, ts DateTime) ENGINE = MergeTree ORDER BY id",
+			want:  []chsql.TableRef{{Database: "db", Table: "t"}},
+		},
+		{
+			name:  "query parameter as table",
+			query: "SELECT * FROM {table:Identifier} WHERE x = 1",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, chsql.ExtractReferences(tt.query).Tables)
+		})
+	}
+}
+
+func TestExtractReferencesFunctionsAndDictionaries(t *testing.T) {
+	refs := chsql.ExtractReferences(`
+		SELECT dictGet('geo.countries', 'name', country_id) AS country,
+			dictGetOrDefault("geo.cities", 'name', city_id, 'unknown')
+		FROM numbers(100) AS n
+		JOIN dictionary('geo.regions') AS r ON n.number = r.id
+		WHERE dictHas('geo.countries', country_id)`)
+
+	assert.Equal(t, []string{"numbers", "dictionary"}, refs.TableFunctions)
+	assert.Equal(t, []string{"geo.countries", "geo.regions"}, refs.Dictionaries)
+}
+
+func TestExtractReferencesColumns(t *testing.T) {
+	refs := chsql.ExtractReferences(`
+		WITH 10 AS top_n
+		SELECT
+			e.user_id,
+			u.name AS user_name,
+			count() AS hits,
+			arrayMap(x -> x * 2, e.values) AS doubled,
+			toStartOfInterval(e.ts, INTERVAL 1 hour) AS bucket,
+			CAST(e.amount AS Nullable(Decimal(18, 2))),
+			e.payload::String,
+			DATE '2024-01-01',
+			events.flag
+		FROM analytics.events AS e
+		JOIN users AS u ON e.user_id = u.id
+		WHERE e.date >= today() - 7 AND status IN ('ok', 'done')
+		GROUP BY e.user_id, user_name
+		ORDER BY hits DESC
+		LIMIT top_n
+		SETTINGS max_threads = 8`)
+
+	assert.Equal(t, []chsql.ColumnRef{
+		{Table: "analytics.events", Name: "user_id"},
+		{Table: "users", Name: "name"},
+		{Table: "analytics.events", Name: "values"},
+		{Table: "analytics.events", Name: "ts"},
+		{Table: "analytics.events", Name: "amount"},
+		{Table: "analytics.events", Name: "payload"},
+		{Table: "analytics.events", Name: "flag"},
+		{Table: "users", Name: "id"},
+		{Table: "analytics.events", Name: "date"},
+		{Name: "status"},
+	}, refs.Columns)
+
+	refs = chsql.ExtractReferences("SELECT EXTRACT(YEAR FROM created_at), trim(BOTH ' ' FROM name), substring(s FROM 2) FROM orders")
+	assert.Equal(t, []chsql.ColumnRef{{Name: "created_at"}, {Name: "name"}, {Name: "s"}}, refs.Columns)
+
+	refs = chsql.ExtractReferences("WITH [1, 2] AS ids SELECT * FROM t1 WHERE a IN t2 AND b IN ids AND position('x' IN s) > 0")
+	assert.Equal(t, []chsql.ColumnRef{{Name: "a"}, {Name: "b"}, {Name: "s"}}, refs.Columns)
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := chsql.Tokenize("SELECT `a``b`, 'it''s', 1.5e-3, t.1, {p:UInt8} -- comment\n::String")
+
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.Kind+":"+tok.Text)
+	}
+	assert.Equal(t, []string{
+		"word:SELECT", "ident:a`b", "symbol:,", "string:it's", "symbol:,", "number:1.5e-3", "symbol:,",
+		"word:t", "symbol:.", "number:1", "symbol:,", "param:{p:UInt8}", "symbol:::", "word:String",
+	}, texts)
+}
@@ -0,0 +1,152 @@
+package chsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Statement is one statement of a script, without its terminating semicolon
+type Statement struct {
+	Query string `json:"query"`
+	Line  int    `json:"line"` // 1-based line the statement starts on
+}
+
+// Split cuts a script into statements on the semicolons outside of string literals, quoted identifiers,
+// comments and heredocs ($tag$ ... $tag$). Comments before a statement are left out of it, statements made only of
+// comments and whitespace are dropped.
+func Split(script string) []Statement {
+	var (
+		statements []Statement
+		start      int
+		line       = 1
+		startLine  = 1
+	)
+
+	flush := func(end int) {
+		// The statement begins after the blanks and comments left from the previous one
+		begin := skipBlank(script[:end], start)
+		if query := strings.TrimSpace(script[begin:end]); query != "" {
+			statements = append(statements, Statement{
+				Query: query,
+				Line:  startLine + strings.Count(script[start:begin], "\n"),
+			})
+		}
+	}
+
+	for i := 0; i < len(script); {
+		switch c := script[i]; {
+		case c == '\n':
+			line++
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(script, i, c)
+			line += strings.Count(script[i:end], "\n")
+			i = end
+		case c == '-' && strings.HasPrefix(script[i:], "--"), c == '#':
+			i = skipLineComment(script, i)
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := skipBlockComment(script, i)
+			line += strings.Count(script[i:end], "\n")
+			i = end
+		case c == '$':
+			end, ok := skipHeredoc(script, i)
+			if !ok {
+				i++
+				continue
+			}
+			line += strings.Count(script[i:end], "\n")
+			i = end
+		case c == ';':
+			flush(i)
+			i++
+			start, startLine = i, line
+		default:
+			i++
+		}
+	}
+	flush(len(script))
+
+	return statements
+}
+
+// skipQuoted returns the index after the literal opened at i, quotes are escaped by a backslash or doubled
+func skipQuoted(s string, i int, quote byte) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// skipLineComment returns the index of the newline ending the comment, it is counted by the caller
+func skipLineComment(s string, i int) int {
+	if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(s)
+}
+
+// skipBlockComment returns the index after the comment opened at i, ClickHouse allows them to nest
+func skipBlockComment(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s)-1; j++ {
+		switch {
+		case s[j] == '/' && s[j+1] == '*':
+			depth++
+			j++
+		case s[j] == '*' && s[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// skipHeredoc returns the index after the $tag$ ... $tag$ literal opened at i, ok is false when i does not open one
+func skipHeredoc(s string, i int) (int, bool) {
+	end := strings.IndexByte(s[i+1:], '$')
+	if end < 0 {
+		return 0, false
+	}
+	tag := s[i : i+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return 0, false
+		}
+	}
+
+	body := i + len(tag)
+	closing := strings.Index(s[body:], tag)
+	if closing < 0 {
+		return len(s), true
+	}
+	return body + closing + len(tag), true
+}
+
+// skipBlank returns the index of the first character from i that is neither whitespace nor part of a comment
+func skipBlank(s string, i int) int {
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '-' && strings.HasPrefix(s[i:], "--"), c == '#':
+			i = skipLineComment(s, i)
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			i = skipBlockComment(s, i)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
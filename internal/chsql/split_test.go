@@ -0,0 +1,83 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func queries(statements []chsql.Statement) []string {
+	result := make([]string, len(statements))
+	for i, s := range statements {
+		result[i] = s.Query
+	}
+	return result
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "single statement with trailing semicolon",
+			script: "SELECT 1;",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "several statements",
+			script: "CREATE TABLE t (n UInt8) ENGINE = Memory;\nINSERT INTO t VALUES (1);\n\nSELECT * FROM t",
+			want:   []string{"CREATE TABLE t (n UInt8) ENGINE = Memory", "INSERT INTO t VALUES (1)", "SELECT * FROM t"},
+		},
+		{
+			name:   "semicolons in strings and identifiers",
+			script: `SELECT 'a;b', 'it''s; fine', 'escaped \'; quote', "col;1", ` + "`col;2`" + `; SELECT 2`,
+			want:   []string{`SELECT 'a;b', 'it''s; fine', 'escaped \'; quote', "col;1", ` + "`col;2`", "SELECT 2"},
+		},
+		{
+			name:   "semicolons in comments",
+			script: "SELECT 1 -- first; not a split\n; /* block; /* nested; */ still; */ SELECT 2 # hash; comment\n",
+			want:   []string{"SELECT 1 -- first; not a split", "SELECT 2 # hash; comment"},
+		},
+		{
+			name:   "heredocs",
+			script: "SELECT $$a;b$$; SELECT $tag$ x; $$ y; $tag$; SELECT 3",
+			want:   []string{"SELECT $$a;b$$", "SELECT $tag$ x; $$ y; $tag$", "SELECT 3"},
+		},
+		{
+			name:   "empty and comment only statements are dropped",
+			script: ";;  ; -- nothing here\n; /* nor here */ ;SELECT 1;",
+			want:   []string{"SELECT 1"},
+		},
+		{
+			name:   "unterminated string keeps the rest",
+			script: "SELECT 1; SELECT 'open; SELECT 2",
+			want:   []string{"SELECT 1", "SELECT 'open; SELECT 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, queries(chsql.Split(tt.script)))
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	statements := chsql.Split("SELECT 1;\n\n  SELECT 'a\nb';\n-- comment\nSELECT 3")
+	assert.Equal(t, []int{1, 3, 6}, []int{statements[0].Line, statements[1].Line, statements[2].Line})
+
+	// A comment after the semicolon belongs to neither statement
+	statements = chsql.Split("SELECT 'a;b'; -- x;\nSELECT $$;$$")
+	assert.Equal(t, []chsql.Statement{{Query: "SELECT 'a;b'", Line: 1}, {Query: "SELECT $$;$$", Line: 2}}, statements)
+}
+
+func TestReturnsRows(t *testing.T) {
+	assert.True(t, chsql.ReturnsRows("-- list\n(SELECT 1) UNION ALL (SELECT 2)"))
+	assert.True(t, chsql.ReturnsRows("with x AS (SELECT 1) SELECT * FROM x"))
+	assert.True(t, chsql.ReturnsRows("SHOW TABLES"))
+	assert.False(t, chsql.ReturnsRows("/* seed */ INSERT INTO t SELECT 1"))
+	assert.False(t, chsql.ReturnsRows("ALTER TABLE t DELETE WHERE 1"))
+}
@@ -0,0 +1,20 @@
+package chsql
+
+import "strings"
+
+// TrimTail returns the statement without its trailing semicolons, comments and FORMAT clause, what is left can be
+// wrapped in a subquery or put after EXPLAIN
+func TrimTail(query string) string {
+	tokens := Tokenize(query)
+	for len(tokens) > 0 && tokens[len(tokens)-1].Text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if n := len(tokens); n >= 2 && tokens[n-2].Kind == TokenWord && strings.EqualFold(tokens[n-2].Text, "FORMAT") &&
+		(tokens[n-1].Kind == TokenWord || tokens[n-1].Kind == TokenIdent) {
+		tokens = tokens[:n-2]
+	}
+	if len(tokens) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(query[:tokenEnd(query, tokens[len(tokens)-1])])
+}
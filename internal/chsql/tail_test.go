@@ -0,0 +1,25 @@
+package chsql_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimTail(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1;;":                                   "SELECT 1",
+		"SELECT 1 -- the answer":                       "SELECT 1",
+		"SELECT 1 /* done */ ;\n-- bye":                "SELECT 1",
+		"SELECT * FROM t FORMAT JSONEachRow;":          "SELECT * FROM t",
+		"SELECT * FROM t format `CSV` -- export":       "SELECT * FROM t",
+		"-- top\nSELECT format FROM t ORDER BY format": "-- top\nSELECT format FROM t ORDER BY format",
+		"SELECT ';' AS s;":                             "SELECT ';' AS s",
+		"-- only a comment":                            "",
+	}
+
+	for query, want := range tests {
+		assert.Equal(t, want, chsql.TrimTail(query), query)
+	}
+}
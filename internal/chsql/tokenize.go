@@ -0,0 +1,161 @@
+package chsql
+
+import "strings"
+
+// Kinds of token
+const (
+	TokenWord   = "word"   // keyword or bare identifier
+	TokenIdent  = "ident"  // `quoted` or "quoted" identifier, Text holds it unquoted
+	TokenString = "string" // 'literal' or $heredoc$, Text holds it unquoted
+	TokenNumber = "number"
+	TokenParam  = "param" // {name:Type} placeholder
+	TokenSymbol = "symbol"
+)
+
+// Token is a piece of a query, comments and whitespace are never tokens
+type Token struct {
+	Kind string
+	Text string
+	Pos  int // byte offset in the query
+}
+
+// Tokenize cuts a query into tokens. It never fails, an unterminated literal runs to the end of the query.
+func Tokenize(query string) []Token {
+	var tokens []Token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			i = skipLineComment(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+		case c == '\'':
+			end := skipQuoted(query, i, c)
+			tokens = append(tokens, Token{Kind: TokenString, Text: unquote(query[i:end]), Pos: i})
+			i = end
+		case c == '`' || c == '"':
+			end := skipQuoted(query, i, c)
+			tokens = append(tokens, Token{Kind: TokenIdent, Text: unquote(query[i:end]), Pos: i})
+			i = end
+		case c == '$':
+			end, ok := skipHeredoc(query, i)
+			if !ok {
+				tokens = append(tokens, Token{Kind: TokenSymbol, Text: "$", Pos: i})
+				i++
+				continue
+			}
+			tag := strings.IndexByte(query[i+1:], '$') + 2
+			tokens = append(tokens, Token{Kind: TokenString, Text: strings.TrimSuffix(query[i+tag:end], query[i:i+tag]), Pos: i})
+			i = end
+		case c == '{':
+			if _, end, ok := parsePlaceholder(query, i); ok {
+				tokens = append(tokens, Token{Kind: TokenParam, Text: query[i:end], Pos: i})
+				i = end
+				continue
+			}
+			tokens = append(tokens, Token{Kind: TokenSymbol, Text: "{", Pos: i})
+			i++
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(query) && isDigit(query[i+1]) && !afterName(tokens):
+			end := scanNumber(query, i)
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: query[i:end], Pos: i})
+			i = end
+		case isWordChar(c) || c >= 0x80:
+			end := i
+			for end < len(query) && (isWordChar(query[end]) || query[end] >= 0x80) {
+				end++
+			}
+			tokens = append(tokens, Token{Kind: TokenWord, Text: query[i:end], Pos: i})
+			i = end
+		default:
+			end := i + 1
+			for _, op := range []string{"::", "->", "<=", ">=", "!=", "<>", "==", "||"} {
+				if strings.HasPrefix(query[i:], op) {
+					end = i + len(op)
+					break
+				}
+			}
+			tokens = append(tokens, Token{Kind: TokenSymbol, Text: query[i:end], Pos: i})
+			i = end
+		}
+	}
+	return tokens
+}
+
+// Is reports whether the token is the keyword, in any case
+func (t Token) Is(keyword string) bool {
+	return t.Kind == TokenWord && strings.EqualFold(t.Text, keyword)
+}
+
+// IsName reports whether the token can name a table or a column
+func (t Token) IsName() bool {
+	return t.Kind == TokenWord || t.Kind == TokenIdent
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// afterName tells a tuple element access (t.1) from a number (.5)
+func afterName(tokens []Token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.IsName() || last.Kind == TokenSymbol && (last.Text == ")" || last.Text == "]")
+}
+
+// scanNumber returns the index after the number at i: decimals, exponents and 0x / 0b literals
+func scanNumber(s string, i int) int {
+	end := i
+	for end < len(s) {
+		c := s[end]
+		switch {
+		case isWordChar(c) || c == '.':
+			end++
+		case (c == '+' || c == '-') && (s[end-1] == 'e' || s[end-1] == 'E') && !strings.HasPrefix(strings.ToLower(s[i:]), "0x"):
+			end++
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// unquote strips the quotes of a literal or identifier and resolves its escapes
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	quote := s[0]
+	body := s[1:]
+	if body[len(body)-1] == quote {
+		body = body[:len(body)-1]
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\\' && i+1 < len(body):
+			i++
+			switch body[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '0':
+				sb.WriteByte(0)
+			default:
+				sb.WriteByte(body[i])
+			}
+		case c == quote && i+1 < len(body) && body[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,266 @@
+package export
+
+import (
+	"encoding/csv"
+	gojson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Download formats, Parquet is produced by the server, the others by the encoders below
+const (
+	FormatCSV         = "csv"
+	FormatTSV         = "tsv"
+	FormatJSONEachRow = "jsoneachrow"
+	FormatParquet     = "parquet"
+	FormatExcel       = "xlsx"
+)
+
+// ErrLimitReached stops an export once the file reached its maximum size or the sheet its maximum rows
+var ErrLimitReached = errors.New("export limit reached")
+
+// Format describes how a download is named and served
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	// ClickHouse output format used when the server writes the file itself
+	ServerFormat string
+}
+
+var formats = map[string]Format{
+	FormatCSV:         {Name: FormatCSV, Extension: "csv", ContentType: "text/csv; charset=utf-8"},
+	FormatTSV:         {Name: FormatTSV, Extension: "tsv", ContentType: "text/tab-separated-values; charset=utf-8"},
+	FormatJSONEachRow: {Name: FormatJSONEachRow, Extension: "jsonl", ContentType: "application/x-ndjson"},
+	FormatParquet:     {Name: FormatParquet, Extension: "parquet", ContentType: "application/vnd.apache.parquet", ServerFormat: "Parquet"},
+	FormatExcel:       {Name: FormatExcel, Extension: "xlsx", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+}
+
+// Lookup returns the format by name, case insensitive
+func Lookup(name string) (Format, error) {
+	format, ok := formats[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Format{}, fmt.Errorf("unsupported export format %q, use csv, tsv, jsoneachrow, parquet or xlsx", name)
+	}
+	return format, nil
+}
+
+// Encoder writes a result row by row, Close completes the file
+type Encoder interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewEncoder starts a file with the header of columns, formats written by the server have no encoder
+func NewEncoder(format Format, w io.Writer, columns []string) (Encoder, error) {
+	switch format.Name {
+	case FormatCSV:
+		return newCSVEncoder(w, columns)
+	case FormatTSV:
+		return newTSVEncoder(w, columns)
+	case FormatJSONEachRow:
+		return &jsonEachRowEncoder{w: w, columns: columns}, nil
+	case FormatExcel:
+		return newXLSXEncoder(w, columns)
+	default:
+		return nil, fmt.Errorf("format %q is not encoded locally", format.Name)
+	}
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVEncoder(w io.Writer, columns []string) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := e.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) WriteRow(values []interface{}) error {
+	for i, v := range values {
+		// NULL is left empty, the way spreadsheets expect it
+		e.record[i], _ = textValue(v)
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// tsvEncoder follows ClickHouse TabSeparatedWithNames: escaped tabs and newlines, NULL as \N
+type tsvEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func newTSVEncoder(w io.Writer, columns []string) (*tsvEncoder, error) {
+	e := &tsvEncoder{w: w}
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	if err := e.WriteRow(values); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *tsvEncoder) WriteRow(values []interface{}) error {
+	e.buf = e.buf[:0]
+	for i, v := range values {
+		if i > 0 {
+			e.buf = append(e.buf, '\t')
+		}
+		text, ok := textValue(v)
+		if !ok {
+			e.buf = append(e.buf, `\N`...)
+			continue
+		}
+		e.buf = append(e.buf, tsvEscaper.Replace(text)...)
+	}
+	e.buf = append(e.buf, '\n')
+
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *tsvEncoder) Close() error {
+	return nil
+}
+
+// jsonEachRowEncoder writes one object per line, keys keep the column order
+type jsonEachRowEncoder struct {
+	w       io.Writer
+	columns []string
+	buf     []byte
+}
+
+func (e *jsonEachRowEncoder) WriteRow(values []interface{}) error {
+	e.buf = append(e.buf[:0], '{')
+	for i, v := range values {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		key, _ := gojson.Marshal(e.columns[i])
+		e.buf = append(e.buf, key...)
+		e.buf = append(e.buf, ':')
+		e.buf = append(e.buf, jsonValue(v)...)
+	}
+	e.buf = append(e.buf, '}', '\n')
+
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *jsonEachRowEncoder) Close() error {
+	return nil
+}
+
+// deref unwraps the pointers the driver scans Nullable columns into, ok is false for NULL
+func deref(v interface{}) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Interface(), true
+}
+
+// textValue renders a value the way ClickHouse prints it in text formats, ok is false for NULL
+func textValue(v interface{}) (string, bool) {
+	v, ok := deref(v)
+	if !ok {
+		return "", false
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case []byte:
+		return string(value), true
+	case time.Time:
+		return formatTime(value), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float32:
+		return formatFloat(float64(value), 32), true
+	case float64:
+		return formatFloat(value, 64), true
+	case fmt.Stringer:
+		// Decimal, UUID, IPv4/IPv6, big integers
+		return value.String(), true
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		// Arrays, maps and tuples are written as JSON
+		return string(jsonValue(v)), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// jsonValue marshals a value for JSONEachRow, NULL and non finite floats become null
+func jsonValue(v interface{}) []byte {
+	v, ok := deref(v)
+	if !ok {
+		return []byte("null")
+	}
+
+	switch value := v.(type) {
+	case time.Time:
+		v = formatTime(value)
+	case float32:
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			return []byte("null")
+		}
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return []byte("null")
+		}
+	}
+
+	data, err := gojson.Marshal(v)
+	if err != nil {
+		data, _ = gojson.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+func formatTime(t time.Time) string {
+	if t.Nanosecond() == 0 {
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return t.Format("2006-01-02 15:04:05.999999999")
+}
+
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
@@ -0,0 +1,75 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, name string) []byte {
+	format, err := export.Lookup(name)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	encoder, err := export.NewEncoder(format, &buf, []string{"id", "name", "created_at"})
+	require.NoError(t, err)
+
+	var missing *string
+	comment := "tab\there, \"quoted\""
+	created := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	require.NoError(t, encoder.WriteRow([]interface{}{uint64(1), &comment, created}))
+	require.NoError(t, encoder.WriteRow([]interface{}{uint64(2), missing, created}))
+	require.NoError(t, encoder.Close())
+	return buf.Bytes()
+}
+
+func TestEncoders(t *testing.T) {
+	assert.Equal(t, "id,name,created_at\n"+
+		"1,\"tab\there, \"\"quoted\"\"\",2024-05-01 10:30:00\n"+
+		"2,,2024-05-01 10:30:00\n", string(encode(t, "csv")))
+
+	assert.Equal(t, "id\tname\tcreated_at\n"+
+		"1\ttab\\there, \"quoted\"\t2024-05-01 10:30:00\n"+
+		"2\t\\N\t2024-05-01 10:30:00\n", string(encode(t, "TSV")))
+
+	assert.Equal(t, `{"id":1,"name":"tab\there, \"quoted\"","created_at":"2024-05-01 10:30:00"}`+"\n"+
+		`{"id":2,"name":null,"created_at":"2024-05-01 10:30:00"}`+"\n", string(encode(t, "jsoneachrow")))
+}
+
+func TestExcelEncoder(t *testing.T) {
+	data := encode(t, "xlsx")
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var sheet string
+	names := make([]string, 0, len(archive.File))
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			sheet = string(content)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}, names)
+	assert.Contains(t, sheet, `<c><v>1</v></c>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">tab&#x9;here, &#34;quoted&#34;</t>`)
+	assert.Contains(t, sheet, `<c/>`)
+	assert.Contains(t, sheet, `</sheetData></worksheet>`)
+}
+
+func TestLookupUnknownFormat(t *testing.T) {
+	_, err := export.Lookup("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// Excel refuses sheets longer than this, the header takes the first row
+	xlsxMaxRows = 1048576
+	// Longer cell texts are cut, Excel would report the file as corrupt
+	xlsxMaxCellChars = 32767
+)
+
+// The static parts of a single sheet workbook, the sheet itself is streamed
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Result" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxEncoder writes a minimal Office Open XML workbook with inline strings, so no shared string table is kept in memory
+type xlsxEncoder struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	buf   bytes.Buffer
+	rows  int
+}
+
+func newXLSXEncoder(w io.Writer, columns []string) (*xlsxEncoder, error) {
+	e := &xlsxEncoder{zip: zip.NewWriter(w)}
+
+	for _, part := range xlsxParts {
+		f, err := e.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := e.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e.sheet = sheet
+
+	if _, err := io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := e.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxEncoder) WriteRow(values []interface{}) error {
+	if e.rows >= xlsxMaxRows {
+		return ErrLimitReached
+	}
+	e.rows++
+
+	e.buf.Reset()
+	e.buf.WriteString(`<row>`)
+	for _, v := range values {
+		if number, ok := numericValue(v); ok {
+			e.buf.WriteString(`<c><v>`)
+			e.buf.WriteString(number)
+			e.buf.WriteString(`</v></c>`)
+			continue
+		}
+
+		text, ok := textValue(v)
+		if !ok {
+			// NULL is an empty cell
+			e.buf.WriteString(`<c/>`)
+			continue
+		}
+		if len(text) > xlsxMaxCellChars {
+			if runes := []rune(text); len(runes) > xlsxMaxCellChars {
+				text = string(runes[:xlsxMaxCellChars])
+			}
+		}
+		e.buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&e.buf, []byte(text)); err != nil {
+			return err
+		}
+		e.buf.WriteString(`</t></is></c>`)
+	}
+	e.buf.WriteString(`</row>`)
+
+	_, err := e.sheet.Write(e.buf.Bytes())
+	return err
+}
+
+func (e *xlsxEncoder) Close() error {
+	if _, err := io.WriteString(e.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
+
+// numericValue returns the number cell of integer and finite float values
+func numericValue(v interface{}) (string, bool) {
+	v, ok := deref(v)
+	if !ok {
+		return "", false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits()), true
+	}
+	return "", false
+}
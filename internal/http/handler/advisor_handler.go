@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	gojson "encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/presenter/json"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+)
+
+type AdvisorHandler struct {
+	presenter json.JsonPresenter
+	usecase   usecase.AdvisorUsecase
+}
+
+func NewAdvisorHandler(presenter json.JsonPresenter, usecase usecase.AdvisorUsecase) *AdvisorHandler {
+	return &AdvisorHandler{
+		presenter: presenter,
+		usecase:   usecase,
+	}
+}
+
+func (h *AdvisorHandler) Register(api fiber.Router) {
+	api.Post("/connections/:id/analyze-query/ai", h.AdviseQuery)
+}
+
+// AdviseQuery streams the answer of the model as NDJSON: delta chunks while it is generated, then the result with
+// the recommendations read from it, or an error
+func (h *AdvisorHandler) AdviseQuery(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	var req entity.AIAnalysisRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	setStreamHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder := gojson.NewEncoder(w)
+		emit := func(chunk *entity.AIAnalysisChunk) error {
+			if err := encoder.Encode(chunk); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		// The request context is gone once the handler returned, the answer stops once nobody reads it
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		result, err := h.usecase.AdviseQuery(ctx, id, req, func(delta string) {
+			if emit(&entity.AIAnalysisChunk{Type: entity.AIChunkDelta, Delta: delta}) != nil {
+				cancel()
+			}
+		})
+		if err != nil {
+			chunk := &entity.AIAnalysisChunk{Type: entity.AIChunkErr, Error: err.Error()}
+			if appErr, ok := err.(apperr.CustomErrorResponse); ok {
+				chunk.Code = appErr.ErrCode
+			}
+			_ = emit(chunk)
+			return
+		}
+		_ = emit(&entity.AIAnalysisChunk{Type: entity.AIChunkResult, Result: result})
+	})
+
+	return nil
+}
@@ -1,10 +1,19 @@
 package handler
 
 import (
+	"bufio"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/export"
 	"github.com/rahmatrdn/go-ch-manager/internal/parser"
 	"github.com/rahmatrdn/go-ch-manager/internal/presenter/json"
 	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
@@ -27,7 +36,12 @@ func NewConnectionHandler(parser parser.Parser, presenter json.JsonPresenter, us
 func (h *ConnectionHandler) Register(api fiber.Router) {
 	connections := api.Group("/connections")
 	connections.Post("", h.CreateConnection)
+	connections.Post("/test", h.TestConnection)
+	connections.Get("/export", h.ExportConnections)
+	connections.Post("/import", h.ImportConnections)
 	connections.Put("/:id", h.UpdateConnection)
+	connections.Delete("/:id", h.DeleteConnection)
+	connections.Post("/:id/duplicate", h.DuplicateConnection)
 	connections.Get("", h.GetConnections)
 	connections.Get("/:id/status", h.GetConnectionStatus)
 	connections.Get("/:id/tables", h.GetConnectionTables)
@@ -36,7 +50,17 @@ func (h *ConnectionHandler) Register(api fiber.Router) {
 	connections.Post("/:id/compare-query", h.CompareQueries)
 	connections.Get("/:id/history", h.GetConnectionHistory)
 	connections.Post("/:id/query", h.HandleExecuteQuery)
+	connections.Post("/:id/query/stream", h.StreamQuery)
+	connections.Post("/:id/query/script", h.ExecuteScript)
+	connections.Post("/:id/query/parameters", h.QueryParameters)
+	connections.Get("/:id/query/cursors/:cursor", h.FetchQueryCursor)
+	connections.Delete("/:id/query/cursors/:cursor", h.CloseQueryCursor)
+	connections.Post("/:id/query/export", h.ExportQuery)
+	connections.Post("/:id/queries/:query_id/cancel", h.CancelQuery)
 	connections.Post("/:id/analyze-query", h.AnalyzeQuery)
+	connections.Post("/:id/explain-query", h.ExplainQuery)
+
+	api.Get("/pool", h.GetPoolStats)
 }
 
 func (h *ConnectionHandler) CreateConnection(c *fiber.Ctx) error {
@@ -55,7 +79,7 @@ func (h *ConnectionHandler) CreateConnection(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
-	return h.presenter.BuildSuccess(c, conn, "Connection Created", 201)
+	return h.presenter.BuildSuccess(c, conn.Redact(), "Connection Created", 201)
 }
 
 func (h *ConnectionHandler) UpdateConnection(c *fiber.Ctx) error {
@@ -74,7 +98,91 @@ func (h *ConnectionHandler) UpdateConnection(c *fiber.Ctx) error {
 		return h.presenter.BuildError(c, err)
 	}
 
-	return h.presenter.BuildSuccess(c, conn, "Connection Updated", 200)
+	return h.presenter.BuildSuccess(c, conn.Redact(), "Connection Updated", 200)
+}
+
+func (h *ConnectionHandler) DeleteConnection(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+
+	if err := h.usecase.DeleteConnection(c.Context(), id); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, nil, "Connection Deleted", 200)
+}
+
+func (h *ConnectionHandler) DuplicateConnection(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+
+	conn, err := h.usecase.DuplicateConnection(c.Context(), id)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, conn, "Connection Duplicated", 201)
+}
+
+// TestConnection checks an unsaved payload, failures are reported in the diagnostics rather than as an error
+func (h *ConnectionHandler) TestConnection(c *fiber.Ctx) error {
+	var conn entity.CHConnection
+	if err := c.BodyParser(&conn); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	result, err := h.usecase.TestConnection(c.Context(), &conn)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Connection Tested", 200)
+}
+
+// ExportConnections downloads every saved connection as ?format=json|yaml. Passwords are never sent over HTTP, only
+// the CLI exports them.
+func (h *ConnectionHandler) ExportConnections(c *fiber.Ctx) error {
+	format := c.Query("format", "yaml")
+
+	file, err := h.usecase.ExportConnections(c.Context(), false)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	data, err := usecase.MarshalConnectionProfiles(file, format)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Attachment(fmt.Sprintf("ch-manager-connections-%s.%s", file.ExportedAt.Format("20060102-150405"), format))
+	return c.Send(data)
+}
+
+// ImportConnections accepts the file as an uploaded "file" field or as the raw request body.
+// Saved connections with the same name are replaced unless ?overwrite=false.
+func (h *ConnectionHandler) ImportConnections(c *fiber.Ctx) error {
+	data := c.Body()
+	if upload, err := c.FormFile("file"); err == nil {
+		f, err := upload.Open()
+		if err != nil {
+			return h.presenter.BuildError(c, err)
+		}
+		defer f.Close()
+
+		if data, err = io.ReadAll(f); err != nil {
+			return h.presenter.BuildError(c, err)
+		}
+	}
+
+	file, err := usecase.UnmarshalConnectionProfiles(data)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	result, err := h.usecase.ImportConnections(c.Context(), file, c.QueryBool("overwrite", true))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Connections Imported", 200)
 }
 
 func (h *ConnectionHandler) GetConnections(c *fiber.Ctx) error {
@@ -94,6 +202,14 @@ func (h *ConnectionHandler) GetConnectionStatus(c *fiber.Ctx) error {
 	return h.presenter.BuildSuccess(c, map[string]string{"status": status}, "Status Retrieved", 200)
 }
 
+func (h *ConnectionHandler) GetPoolStats(c *fiber.Ctx) error {
+	stats, err := h.usecase.GetPoolStats(c.Context())
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	return h.presenter.BuildSuccess(c, stats, "Pool Stats Retrieved", 200)
+}
+
 func (h *ConnectionHandler) GetConnectionTables(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
 	db := c.Query("db")
@@ -114,19 +230,14 @@ func (h *ConnectionHandler) GetTableSchema(c *fiber.Ctx) error {
 	return h.presenter.BuildSuccess(c, schema, "Schema Retrieved", 200)
 }
 
-type CompareRequest struct {
-	Query1 string `json:"query1"`
-	Query2 string `json:"query2"`
-}
-
 func (h *ConnectionHandler) CompareQueries(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
-	var req CompareRequest
+	var req entity.CompareRequest
 	if err := c.BodyParser(&req); err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	result, err := h.usecase.CompareQueries(c.Context(), id, req.Query1, req.Query2)
+	result, err := h.usecase.CompareQueries(c.Context(), id, req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
@@ -134,33 +245,293 @@ func (h *ConnectionHandler) CompareQueries(c *fiber.Ctx) error {
 	return h.presenter.BuildSuccess(c, result, "Comparison Completed", 200)
 }
 
-type ExecuteQueryRequest struct {
-	Query string `json:"query"`
+// HandleExecuteQuery runs a console query and answers with its result. The response is streamed, a space is written
+// every second while the query runs so a closed browser connection makes the write fail and cancels the query. The
+// status is committed with the first byte, an error of the query comes as the usual error body under a 200.
+func (h *ConnectionHandler) HandleExecuteQuery(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	var req entity.QueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	var err error
+	if req.QueryID, err = usecase.ResolveQueryID(req.QueryID); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The fasthttp context outlives an aborted request, the query stops with the failed write instead
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		type executed struct {
+			result *entity.QueryResult
+			err    error
+		}
+		done := make(chan executed, 1)
+		go func() {
+			result, err := h.usecase.ExecuteQuery(ctx, id, req)
+			done <- executed{result, err}
+		}()
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case res := <-done:
+				var body interface{} = &json.ResponseBody{Data: res.result, Message: "Query Executed", Code: entity.SUCCESS_CODE}
+				if res.err != nil {
+					_, body = json.ErrorBody(res.err)
+				}
+				_ = gojson.NewEncoder(w).Encode(body)
+				_ = w.Flush()
+				return
+			case <-ticker.C:
+				// Whitespace before the JSON body is still valid JSON
+				err := w.WriteByte(' ')
+				if err == nil {
+					err = w.Flush()
+				}
+				if err != nil {
+					cancel()
+					_, _ = h.usecase.CancelQuery(context.Background(), id, req.QueryID)
+					<-done
+					return
+				}
+			}
+		}
+	})
+
+	return nil
 }
 
-func (h *ConnectionHandler) HandleExecuteQuery(c *fiber.Ctx) error {
+type ExecuteScriptRequest struct {
+	Script      string            `json:"script"`
+	QueryID     string            `json:"query_id"`
+	Parameters  map[string]string `json:"params"`
+	Settings    map[string]string `json:"settings"`
+	StopOnError bool              `json:"stop_on_error"`
+	Confirm     bool              `json:"confirm"`
+}
+
+// ExecuteScript runs the ;-separated statements of a script in order and returns the result of each one
+func (h *ConnectionHandler) ExecuteScript(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	var req ExecuteScriptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	queryID, err := usecase.ResolveQueryID(req.QueryID)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	script := entity.QueryRequest{QueryID: queryID, Query: req.Script, Parameters: req.Parameters, Settings: req.Settings, Confirm: req.Confirm}
+	result, err := h.usecase.ExecuteScript(c.Context(), id, script, req.StopOnError)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Script Executed", 200)
+}
+
+type StreamQueryRequest struct {
+	entity.QueryRequest
+	PageSize int `json:"page_size"`
+}
+
+// StreamQuery runs a console query and streams the first page as newline delimited JSON chunks.
+// Progress chunks are sent every second until the first rows arrive, a closed browser connection
+// makes them fail and cancels the query. When more rows are left the last chunk carries a cursor for FetchQueryCursor.
+func (h *ConnectionHandler) StreamQuery(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	var req StreamQueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	queryID, err := usecase.ResolveQueryID(req.QueryID)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	req.QueryID = queryID
+
+	setStreamHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		emit := chunkEmitter(w)
+
+		type opened struct {
+			meta     *entity.QueryChunk
+			cursorID string
+			err      error
+		}
+		result := make(chan opened, 1)
+		go func() {
+			// The request context is gone once the handler returned, the stream runs on its own
+			meta, cursorID, err := h.usecase.OpenQueryCursor(context.Background(), id, req.QueryRequest)
+			result <- opened{meta, cursorID, err}
+		}()
+
+		start := time.Now()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		progress := &entity.QueryChunk{Type: entity.QueryChunkProgress, QueryID: queryID}
+		aborted := emit(progress) != nil
+		for !aborted {
+			select {
+			case res := <-result:
+				if res.err != nil {
+					chunk := &entity.QueryChunk{Type: entity.QueryChunkErr, QueryID: queryID, Error: res.err.Error()}
+					if appErr, ok := res.err.(apperr.CustomErrorResponse); ok {
+						chunk.Code = appErr.ErrCode
+					}
+					_ = emit(chunk)
+					return
+				}
+				h.readCursor(w, id, res.cursorID, req.PageSize, res.meta)
+				return
+			case <-ticker.C:
+				progress.ElapsedMs = time.Since(start).Milliseconds()
+				aborted = emit(progress) != nil
+			}
+		}
+
+		// Nobody is listening anymore, stop the query and drop the cursor if it opened meanwhile
+		_, _ = h.usecase.CancelQuery(context.Background(), id, queryID)
+		if res := <-result; res.err == nil {
+			h.usecase.CloseQueryCursor(id, res.cursorID)
+		}
+	})
+
+	return nil
+}
+
+func (h *ConnectionHandler) FetchQueryCursor(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	cursorID := c.Params("cursor")
+	pageSize := c.QueryInt("page_size")
+
+	setStreamHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		h.readCursor(w, id, cursorID, pageSize, nil)
+	})
+
+	return nil
+}
+
+func (h *ConnectionHandler) CloseQueryCursor(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	h.usecase.CloseQueryCursor(id, c.Params("cursor"))
+	return h.presenter.BuildSuccess(c, nil, "Cursor Closed", 200)
+}
+
+// CancelQuery stops a running console query by the query ID it was started with
+func (h *ConnectionHandler) CancelQuery(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+
+	result, err := h.usecase.CancelQuery(c.Context(), id, c.Params("query_id"))
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Query Cancelled", 200)
+}
+
+func setStreamHeaders(c *fiber.Ctx) {
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+}
+
+// chunkEmitter flushes every chunk so the browser can render the first rows right away
+func chunkEmitter(w *bufio.Writer) func(*entity.QueryChunk) error {
+	encoder := gojson.NewEncoder(w)
+	return func(chunk *entity.QueryChunk) error {
+		if err := encoder.Encode(chunk); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+}
+
+// readCursor writes a page of the cursor as it is read, after the meta chunk of a query that was just opened
+func (h *ConnectionHandler) readCursor(w *bufio.Writer, id int64, cursorID string, pageSize int, first *entity.QueryChunk) {
+	emit := chunkEmitter(w)
+
+	if first != nil {
+		if err := emit(first); err != nil {
+			h.usecase.CloseQueryCursor(id, cursorID)
+			return
+		}
+	}
+
+	if err := h.usecase.ReadQueryCursor(context.Background(), id, cursorID, pageSize, emit); err != nil {
+		_ = emit(&entity.QueryChunk{Type: entity.QueryChunkErr, Error: err.Error()})
+	}
+}
+
+type ExportQueryRequest struct {
+	entity.QueryRequest
+	Format string `json:"format"`
+}
+
+// ExportQuery re-runs a console query and sends the result as a file download.
+// The format comes from the body or ?format=, csv when none is given.
+func (h *ConnectionHandler) ExportQuery(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
-	var req ExecuteQueryRequest
+	var req ExportQueryRequest
 	if err := c.BodyParser(&req); err != nil {
 		return h.presenter.BuildError(c, err)
 	}
+	if req.Format == "" {
+		req.Format = c.Query("format", export.FormatCSV)
+	}
 
-	result, err := h.usecase.ExecuteQuery(c.Context(), id, req.Query)
+	file, err := h.usecase.OpenQueryExport(c.Context(), id, req.QueryRequest, req.Format)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	return h.presenter.BuildSuccess(c, result, "Query Executed", 200)
+	c.Attachment(file.Filename)
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// Headers are gone already, a failure can only end the download early
+		if err := file.Stream(w); err != nil {
+			log.Printf("Export %s of connection %d stopped: %v\n", file.Filename, id, err)
+		}
+		_ = w.Flush()
+	})
+
+	return nil
+}
+
+// QueryParameters lists the {name:Type} placeholders of a query, for the console to ask their values
+func (h *ConnectionHandler) QueryParameters(c *fiber.Ctx) error {
+	var req entity.QueryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, h.usecase.QueryParameters(req.Query), "Parameters Detected", 200)
 }
 
 func (h *ConnectionHandler) AnalyzeQuery(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
-	var req ExecuteQueryRequest
+	var req entity.AnalyzeRequest
 	if err := c.BodyParser(&req); err != nil {
 		return h.presenter.BuildError(c, err)
 	}
 
-	result, err := h.usecase.AnalyzeQuery(c.Context(), id, req.Query)
+	result, err := h.usecase.AnalyzeQuery(c.Context(), id, req)
 	if err != nil {
 		return h.presenter.BuildError(c, err)
 	}
@@ -168,6 +539,22 @@ func (h *ConnectionHandler) AnalyzeQuery(c *fiber.Ctx) error {
 	return h.presenter.BuildSuccess(c, result, "Analysis Generated", 200)
 }
 
+// ExplainQuery runs the kind of EXPLAIN the console picked and returns its steps as a tree
+func (h *ConnectionHandler) ExplainQuery(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	var req entity.ExplainRequest
+	if err := c.BodyParser(&req); err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	result, err := h.usecase.ExplainQuery(c.Context(), id, req)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+
+	return h.presenter.BuildSuccess(c, result, "Query Explained", 200)
+}
+
 func (h *ConnectionHandler) GetConnectionHistory(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
 	history, err := h.usecase.GetQueryHistory(c.Context(), id)
@@ -9,12 +9,14 @@ import (
 )
 
 type ViewHandler struct {
-	usecase *usecase.ConnectionUsecase
+	usecase       *usecase.ConnectionUsecase
+	healthUsecase usecase.HealthUsecase
 }
 
-func NewViewHandler(usecase *usecase.ConnectionUsecase) *ViewHandler {
+func NewViewHandler(usecase *usecase.ConnectionUsecase, healthUsecase usecase.HealthUsecase) *ViewHandler {
 	return &ViewHandler{
-		usecase: usecase,
+		usecase:       usecase,
+		healthUsecase: healthUsecase,
 	}
 }
 
@@ -59,8 +61,12 @@ func (h *ViewHandler) Dashboard(c *fiber.Ctx) error {
 		return c.Status(500).SendString(err.Error())
 	}
 
+	// Badges are best effort, the dashboard still renders without health data
+	health, _ := h.healthUsecase.GetLatestStatuses(c.Context())
+
 	return h.render(c, "index", fiber.Map{
 		"Connections": conns,
+		"Health":      health,
 		"PageTitle":   "Dashboard",
 	})
 }
@@ -79,6 +85,7 @@ func (h *ViewHandler) HandleCreateConnection(c *fiber.Ctx) error {
 
 	// Checkbox handling: HTML forms don't send anything for unchecked boxes
 	conn.UseSSL = c.FormValue("use_ssl") == "on"
+	conn.TLSSkipVerify = c.FormValue("tls_skip_verify") == "on"
 
 	if conn.Label == "" {
 		conn.Label = "DEVELOPMENT"
@@ -88,7 +95,7 @@ func (h *ViewHandler) HandleCreateConnection(c *fiber.Ctx) error {
 		return h.render(c, "connections/create", fiber.Map{
 			"PageTitle": "New Connection",
 			"Error":     err.Error(),
-			"Form":      conn,
+			"Form":      conn.Redact(),
 		})
 	}
 	return c.Redirect("/")
@@ -129,13 +136,20 @@ func (h *ViewHandler) HandleUpdateConnection(c *fiber.Ctx) error {
 
 	conn.ID = id
 	conn.UseSSL = c.FormValue("use_ssl") == "on"
+	conn.TLSSkipVerify = c.FormValue("tls_skip_verify") == "on"
+	conn.ClearPassword = c.FormValue("clear_password") == "on"
 
 	if conn.Label == "" {
 		conn.Label = "DEVELOPMENT"
 	}
 
 	if err := h.usecase.UpdateConnection(c.Context(), id, &conn); err != nil {
-		return c.Status(500).SendString(err.Error())
+		// Re-render the form so TLS / connection errors are shown next to the fields
+		return h.render(c, "connections/edit", fiber.Map{
+			"Connection": conn.Redact(),
+			"PageTitle":  "Edit Connection",
+			"Error":      err.Error(),
+		})
 	}
 	return c.Redirect("/")
 }
@@ -261,9 +275,10 @@ func (h *ViewHandler) ComparePage(c *fiber.Ctx) error {
 func (h *ViewHandler) SaveCompareFavorite(c *fiber.Ctx) error {
 	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
 	var input struct {
-		Title  string `json:"title"`
-		Query1 string `json:"query1"`
-		Query2 string `json:"query2"`
+		Title      string            `json:"title"`
+		Query1     string            `json:"query1"`
+		Query2     string            `json:"query2"`
+		Parameters map[string]string `json:"params"`
 	}
 
 	if err := c.BodyParser(&input); err != nil {
@@ -275,6 +290,7 @@ func (h *ViewHandler) SaveCompareFavorite(c *fiber.Ctx) error {
 		Title:        input.Title,
 		Query1:       input.Query1,
 		Query2:       input.Query2,
+		Parameters:   input.Parameters,
 	}
 
 	if err := h.usecase.SaveFavoriteComparison(c.Context(), fav); err != nil {
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rahmatrdn/go-ch-manager/internal/presenter/json"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+)
+
+type HealthHandler struct {
+	presenter json.JsonPresenter
+	usecase   usecase.HealthUsecase
+}
+
+func NewHealthHandler(presenter json.JsonPresenter, usecase usecase.HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		presenter: presenter,
+		usecase:   usecase,
+	}
+}
+
+func (h *HealthHandler) Register(api fiber.Router) {
+	api.Get("/health/connections", h.GetLatestStatuses)
+	api.Get("/connections/:id/health", h.GetStatusHistory)
+}
+
+func (h *HealthHandler) GetLatestStatuses(c *fiber.Ctx) error {
+	statuses, err := h.usecase.GetLatestStatuses(c.Context())
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	return h.presenter.BuildSuccess(c, statuses, "Statuses Retrieved", 200)
+}
+
+func (h *HealthHandler) GetStatusHistory(c *fiber.Ctx) error {
+	id, _ := strconv.ParseInt(c.Params("id"), 10, 64)
+	limit := c.QueryInt("limit", 100)
+
+	history, err := h.usecase.GetStatusHistory(c.Context(), id, limit)
+	if err != nil {
+		return h.presenter.BuildError(c, err)
+	}
+	return h.presenter.BuildSuccess(c, history, "Status History Retrieved", 200)
+}
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
 	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
 )
 
@@ -32,10 +36,31 @@ func (h *ReportHandler) GetSlowQueries(c *fiber.Ctx) error {
 	}
 
 	refresh := c.Query("refresh") == "true"
-	queryKind := c.Query("queryKind", "all")
+	filter := entity.SlowQueryFilter{
+		QueryKind: c.Query("queryKind", "all"),
+		Range:     c.Query("range"),
+		SortBy:    c.Query("sort"),
+		User:      c.Query("user"),
+		Database:  c.Query("database"),
+		Table:     c.Query("table"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("invalid limit %q", limit))
+		}
+	}
+	if filter.From, err = parseReportTime(c.Query("from")); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if filter.To, err = parseReportTime(c.Query("to")); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
 
-	reports, lastRefresh, err := h.reportUsecase.GetTopSlowQueries(c.Context(), connectionID, queryKind, refresh)
+	reports, lastRefresh, err := h.reportUsecase.GetTopSlowQueries(c.Context(), connectionID, filter, refresh)
 	if err != nil {
+		if appErr, ok := err.(apperr.CustomErrorResponse); ok {
+			return c.Status(appErr.HTTPCode).SendString(appErr.Message)
+		}
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
 
@@ -59,8 +84,21 @@ func (h *ReportHandler) GetSlowQueries(c *fiber.Ctx) error {
 		"Reports":            string(reportsJSON),
 		"ConnectionID":       connectionID,
 		"LastRefresh":        lastRefresh,
-		"QueryKind":          queryKind,
+		"QueryKind":          filter.QueryKind,
 		"ActiveMenu":         " reports",
 		"SidebarConnections": connections,
 	}, "layouts/main")
 }
+
+// parseReportTime reads a from / to query parameter, RFC 3339 or the local time of a datetime-local input
+func parseReportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC 3339", value)
+}
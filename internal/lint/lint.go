@@ -0,0 +1,230 @@
+// Package lint checks a query, its EXPLAIN plan and the tables it reads against the rules of the
+// clickhouse-best-practices skill in .agents/skills, each finding names the rule it comes from.
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// Table is a table the query refers to, with the CREATE statement the server returned for it
+type Table struct {
+	Database  string
+	Name      string
+	CreateSQL string
+}
+
+// Input is what the rules look at, ExplainPlan is the EXPLAIN PLAN output and may be empty
+type Input struct {
+	Query       string
+	ExplainPlan string
+	Tables      []Table
+}
+
+// rule checks one best practice, Suggestion is used for the findings that give none
+type rule struct {
+	ID         string
+	Severity   string
+	Suggestion string
+	Check      func(q *query) []entity.LintFinding
+}
+
+var severityRank = map[string]int{
+	entity.LintSeverityCritical: 0,
+	entity.LintSeverityWarning:  1,
+	entity.LintSeverityInfo:     2,
+}
+
+// Check runs every rule, the findings come most severe first and in rule order otherwise
+func Check(in Input) []entity.LintFinding {
+	q := newQuery(in)
+
+	findings := make([]entity.LintFinding, 0)
+	for _, r := range rules {
+		for _, f := range r.Check(q) {
+			f.RuleID = r.ID
+			if f.Severity == "" {
+				f.Severity = r.Severity
+			}
+			if f.Suggestion == "" {
+				f.Suggestion = r.Suggestion
+			}
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	return findings
+}
+
+// table is a table of the input with its parsed definition
+type table struct {
+	Table
+	def chsql.TableDefinition
+}
+
+// FullName is the table as findings name it
+func (t table) FullName() string {
+	if t.Database == "" {
+		return t.Name
+	}
+	return t.Database + "." + t.Name
+}
+
+// query holds the input cut up the way the rules need it
+type query struct {
+	in     Input
+	tokens []chsql.Token
+	depths []int // parenthesis depth of each token
+	refs   chsql.References
+	tables []table
+}
+
+func newQuery(in Input) *query {
+	q := &query{in: in, tokens: chsql.Tokenize(in.Query), refs: chsql.ExtractReferences(in.Query)}
+
+	depth := 0
+	q.depths = make([]int, len(q.tokens))
+	for i, t := range q.tokens {
+		if t.Kind == chsql.TokenSymbol && t.Text == ")" && depth > 0 {
+			depth--
+		}
+		q.depths[i] = depth
+		if t.Kind == chsql.TokenSymbol && t.Text == "(" {
+			depth++
+		}
+	}
+
+	for _, t := range in.Tables {
+		q.tables = append(q.tables, table{Table: t, def: chsql.ParseCreateTable(t.CreateSQL)})
+	}
+	return q
+}
+
+// statement returns the upper-cased keyword the query starts with
+func (q *query) statement() string {
+	return chsql.FirstKeyword(q.in.Query)
+}
+
+// topLevel reports whether a keyword is used outside parentheses
+func (q *query) topLevel(keyword string) bool {
+	for i, t := range q.tokens {
+		if q.depths[i] == 0 && t.Is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// uses reports whether a keyword is used anywhere in the query
+func (q *query) uses(keyword string) bool {
+	for _, t := range q.tokens {
+		if t.Is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keywords that end a clause of a SELECT
+var clauseEnd = map[string]bool{
+	"WHERE": true, "PREWHERE": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true,
+	"OFFSET": true, "SETTINGS": true, "FORMAT": true, "UNION": true, "EXCEPT": true, "INTERSECT": true,
+	"WINDOW": true, "QUALIFY": true, "WITH": true,
+}
+
+// clauseColumns returns the names of the columns used in every clause opened by one of the keywords,
+// GROUP and ORDER are followed by BY. A qualified column counts by its own name.
+func (q *query) clauseColumns(keywords ...string) map[string]bool {
+	columns := make(map[string]bool)
+	for i, t := range q.tokens {
+		if !isAny(t, keywords) {
+			continue
+		}
+		start := i + 1
+		if t.Is("GROUP") || t.Is("ORDER") {
+			if start >= len(q.tokens) || !q.tokens[start].Is("BY") {
+				continue
+			}
+			start++
+		}
+		depth := q.depths[i]
+		for j := start; j < len(q.tokens) && q.depths[j] >= depth; j++ {
+			c := q.tokens[j]
+			if q.depths[j] == depth && c.Kind == chsql.TokenWord && clauseEnd[strings.ToUpper(c.Text)] {
+				break
+			}
+			if c.IsName() && !q.followedBy(j, "(") && !q.followedBy(j, ".") {
+				columns[c.Text] = true
+			}
+		}
+	}
+	return columns
+}
+
+func (q *query) followedBy(i int, symbol string) bool {
+	return i+1 < len(q.tokens) && q.tokens[i+1].Kind == chsql.TokenSymbol && q.tokens[i+1].Text == symbol
+}
+
+// readTables returns the tables of the input the query names right after one of the keywords
+func (q *query) readTables(keywords ...string) []table {
+	var read []table
+	for _, t := range q.tables {
+		for i, tok := range q.tokens {
+			if isAny(tok, keywords) && q.names(i+1, t) {
+				read = append(read, t)
+				break
+			}
+		}
+	}
+	return read
+}
+
+// names reports whether the tokens at i name the table, with or without its database
+func (q *query) names(i int, t table) bool {
+	if i >= len(q.tokens) || !q.tokens[i].IsName() {
+		return false
+	}
+	if q.followedBy(i, ".") && i+2 < len(q.tokens) {
+		return q.tokens[i].Text == t.Database && q.tokens[i+2].Text == t.Name
+	}
+	return q.tokens[i].Text == t.Name
+}
+
+// usedColumns returns the names of the columns the query refers to
+func (q *query) usedColumns() map[string]bool {
+	columns := make(map[string]bool)
+	for _, c := range q.refs.Columns {
+		columns[c.Name] = true
+	}
+	return columns
+}
+
+func isAny(t chsql.Token, keywords []string) bool {
+	for _, k := range keywords {
+		if t.Is(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyColumns returns the names a key expression is computed from, toStartOfHour(ts) gives ts
+func keyColumns(expr string) []string {
+	tokens := chsql.Tokenize(expr)
+	var names []string
+	for i, t := range tokens {
+		if !t.IsName() {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].Kind == chsql.TokenSymbol && tokens[i+1].Text == "(" {
+			continue
+		}
+		names = append(names, t.Text)
+	}
+	return names
+}
@@ -0,0 +1,154 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/lint"
+	"github.com/stretchr/testify/assert"
+)
+
+var events = lint.Table{
+	Database: "analytics",
+	Name:     "events",
+	CreateSQL: `CREATE TABLE analytics.events
+(
+    ` + "`tenant_id`" + ` UInt32,
+    ` + "`ts`" + ` DateTime,
+    ` + "`browser`" + ` String,
+    ` + "`referrer`" + ` Nullable(String),
+    ` + "`user_id`" + ` UInt64
+)
+ENGINE = MergeTree
+ORDER BY (tenant_id, toStartOfHour(ts))`,
+}
+
+var users = lint.Table{
+	Database:  "analytics",
+	Name:      "users",
+	CreateSQL: "CREATE TABLE analytics.users (`id` UInt64, `country` String) ENGINE = ReplacingMergeTree ORDER BY id",
+}
+
+// ruleIDs returns the rule of each finding, in order
+func ruleIDs(findings []entity.LintFinding) []string {
+	ids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	return ids
+}
+
+func TestCheckRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      lint.Input
+		wantIDs []string
+	}{
+		{
+			name:    "filter on the primary key prefix",
+			in:      lint.Input{Query: "SELECT count() FROM analytics.events WHERE tenant_id = 1 AND browser = 'x'", Tables: []lint.Table{events}},
+			wantIDs: []string{},
+		},
+		{
+			name:    "filter skips the primary key",
+			in:      lint.Input{Query: "SELECT count() FROM events WHERE browser = 'x'", Tables: []lint.Table{events}},
+			wantIDs: []string{"schema-pk-filter-on-orderby"},
+		},
+		{
+			name:    "no filter reads everything on purpose",
+			in:      lint.Input{Query: "SELECT count() FROM analytics.events", Tables: []lint.Table{events}},
+			wantIDs: []string{},
+		},
+		{
+			name:    "nullable and string group keys, most severe first",
+			in:      lint.Input{Query: "SELECT browser, count() FROM analytics.events WHERE referrer IS NULL GROUP BY browser", Tables: []lint.Table{events}},
+			wantIDs: []string{"schema-pk-filter-on-orderby", "schema-types-avoid-nullable", "schema-types-lowcardinality"},
+		},
+		{
+			name:    "mutations",
+			in:      lint.Input{Query: "ALTER TABLE analytics.events DELETE WHERE user_id = 1"},
+			wantIDs: []string{"insert-mutation-avoid-delete"},
+		},
+		{
+			name:    "update mutation",
+			in:      lint.Input{Query: "ALTER TABLE analytics.users UPDATE country = 'NL' WHERE id = 1"},
+			wantIDs: []string{"insert-mutation-avoid-update"},
+		},
+		{
+			name:    "lightweight delete is not a mutation",
+			in:      lint.Input{Query: "DELETE FROM analytics.events WHERE tenant_id = 1"},
+			wantIDs: []string{},
+		},
+		{
+			name:    "optimize final",
+			in:      lint.Input{Query: "OPTIMIZE TABLE analytics.events FINAL"},
+			wantIDs: []string{"insert-optimize-avoid-final"},
+		},
+		{
+			name:    "select final is fine",
+			in:      lint.Input{Query: "SELECT * FROM analytics.users FINAL WHERE id = 1", Tables: []lint.Table{users}},
+			wantIDs: []string{},
+		},
+		{
+			name: "filter left above the join",
+			in: lint.Input{
+				Query: "SELECT e.ts FROM analytics.events e JOIN analytics.users u ON e.user_id = u.id WHERE e.tenant_id = 1 OR u.id = 2 SETTINGS join_use_nulls = 1",
+				ExplainPlan: `Expression ((Project names + Projection))
+  Filter ((WHERE + DROP unused columns after JOIN))
+    Expression (Change column names)
+      Join (JOIN FillRightFirst)
+        Expression (Change column names to column identifiers)
+          ReadFromMergeTree (analytics.events)
+        Expression (Change column names to column identifiers)
+          ReadFromMergeTree (analytics.users)
+`,
+				Tables: []lint.Table{events, users},
+			},
+			wantIDs: []string{"query-join-filter-before", "query-join-null-handling"},
+		},
+		{
+			name: "filter pushed below the join",
+			in: lint.Input{
+				Query: "SELECT e.ts FROM analytics.events e JOIN analytics.users u ON e.user_id = u.id WHERE e.tenant_id = 1",
+				ExplainPlan: `Expression ((Project names + Projection))
+  Join (JOIN FillRightFirst)
+    Filter (WHERE)
+      ReadFromMergeTree (analytics.events)
+    ReadFromMergeTree (analytics.users)
+`,
+				Tables: []lint.Table{events, users},
+			},
+			wantIDs: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantIDs, ruleIDs(lint.Check(tt.in)))
+		})
+	}
+}
+
+func TestCheckFinding(t *testing.T) {
+	findings := lint.Check(lint.Input{
+		Query:  "SELECT browser, count() FROM analytics.events WHERE ts > now() - INTERVAL 1 DAY GROUP BY browser",
+		Tables: []lint.Table{events},
+	})
+
+	assert.Equal(t, []entity.LintFinding{
+		{
+			RuleID:     "schema-pk-filter-on-orderby",
+			Severity:   entity.LintSeverityCritical,
+			Table:      "analytics.events",
+			Message:    "The filter does not use tenant_id, the first column of the primary key (tenant_id, toStartOfHour(ts)), so the index of analytics.events cannot skip any granule",
+			Suggestion: "Add a condition on tenant_id to WHERE, or if this filter is common add a projection or skipping index for it",
+		},
+		{
+			RuleID:     "schema-types-lowcardinality",
+			Severity:   entity.LintSeverityInfo,
+			Table:      "analytics.events",
+			Message:    "Plain String columns of analytics.events in GROUP BY: browser, every value is stored, hashed and compared in full",
+			Suggestion: "If they hold fewer than about 10,000 distinct values, declare them LowCardinality(String)",
+		},
+	}, findings)
+}
@@ -0,0 +1,218 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// rules are checked in this order, their IDs are the file names in .agents/skills/clickhouse-best-practices/rules
+var rules = []rule{
+	{
+		ID:         "insert-mutation-avoid-delete",
+		Severity:   entity.LintSeverityCritical,
+		Suggestion: "Use a lightweight DELETE FROM, drop whole partitions with ALTER TABLE ... DROP PARTITION, or cancel rows with a CollapsingMergeTree",
+		Check:      mutation("DELETE"),
+	},
+	{
+		ID:         "insert-mutation-avoid-update",
+		Severity:   entity.LintSeverityCritical,
+		Suggestion: "Insert the new version of the rows into a ReplacingMergeTree and read the latest one with FINAL or argMax",
+		Check:      mutation("UPDATE"),
+	},
+	{
+		ID:       "schema-pk-filter-on-orderby",
+		Severity: entity.LintSeverityCritical,
+		Check:    filterOnPrimaryKey,
+	},
+	{
+		ID:         "insert-optimize-avoid-final",
+		Severity:   entity.LintSeverityWarning,
+		Suggestion: "Let background merges do the work, read deduplicated rows with SELECT ... FINAL instead",
+		Check:      optimizeFinal,
+	},
+	{
+		ID:         "query-join-filter-before",
+		Severity:   entity.LintSeverityWarning,
+		Suggestion: "Filter each side in a subquery before the JOIN, or aggregate it first, so fewer rows are joined",
+		Check:      filterAfterJoin,
+	},
+	{
+		ID:         "schema-types-avoid-nullable",
+		Severity:   entity.LintSeverityInfo,
+		Suggestion: "Unless NULL means something the default value cannot, declare the columns without Nullable and with a DEFAULT",
+		Check:      nullableColumns,
+	},
+	{
+		ID:         "schema-types-lowcardinality",
+		Severity:   entity.LintSeverityInfo,
+		Suggestion: "If they hold fewer than about 10,000 distinct values, declare them LowCardinality(String)",
+		Check:      stringGroupKeys,
+	},
+	{
+		ID:         "query-join-null-handling",
+		Severity:   entity.LintSeverityInfo,
+		Suggestion: "Leave join_use_nulls = 0 so unmatched rows get default values, unless NULL must tell them apart",
+		Check:      joinUseNulls,
+	},
+}
+
+// mutation finds ALTER TABLE ... DELETE or UPDATE
+func mutation(command string) func(q *query) []entity.LintFinding {
+	return func(q *query) []entity.LintFinding {
+		if q.statement() != "ALTER" || !q.topLevel(command) {
+			return nil
+		}
+		return []entity.LintFinding{{
+			Table:   tableName(q.refs),
+			Message: fmt.Sprintf("ALTER TABLE ... %s is a mutation, it rewrites every data part holding a matching row", command),
+		}}
+	}
+}
+
+// filterOnPrimaryKey finds filters that leave out the first column of a table's primary key,
+// the sparse index cannot skip granules and the whole table is read.
+// Joined tables are left to query-join-filter-before, the filter often only applies to the other side.
+func filterOnPrimaryKey(q *query) []entity.LintFinding {
+	filtered := q.clauseColumns("WHERE", "PREWHERE")
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	var findings []entity.LintFinding
+	for _, t := range q.readTables("FROM") {
+		key := t.def.PrimaryKey
+		if !strings.Contains(t.def.Engine, "MergeTree") || len(key) == 0 {
+			continue
+		}
+		first := keyColumns(key[0])
+		used := false
+		for _, name := range first {
+			used = used || filtered[name]
+		}
+		if used || len(first) == 0 {
+			continue
+		}
+		findings = append(findings, entity.LintFinding{
+			Table: t.FullName(),
+			Message: fmt.Sprintf("The filter does not use %s, the first column of the primary key (%s), so the index of %s cannot skip any granule",
+				key[0], strings.Join(key, ", "), t.FullName()),
+			Suggestion: fmt.Sprintf("Add a condition on %s to WHERE, or if this filter is common add a projection or skipping index for it", key[0]),
+		})
+	}
+	return findings
+}
+
+// optimizeFinal finds OPTIMIZE TABLE ... FINAL
+func optimizeFinal(q *query) []entity.LintFinding {
+	if q.statement() != "OPTIMIZE" || !q.topLevel("FINAL") {
+		return nil
+	}
+	return []entity.LintFinding{{
+		Table:   tableName(q.refs),
+		Message: "OPTIMIZE ... FINAL merges every part of each partition into one, whatever their size",
+	}}
+}
+
+// filterAfterJoin finds a Filter step right above a Join step in the EXPLAIN plan,
+// the server could not push the conditions down to the tables
+func filterAfterJoin(q *query) []entity.LintFinding {
+	type step struct {
+		indent int
+		name   string
+	}
+	var steps []step
+	for _, line := range strings.Split(q.in.ExplainPlan, "\n") {
+		name := strings.TrimLeft(line, " ")
+		if name == "" || strings.HasPrefix(name, "--") {
+			continue
+		}
+		steps = append(steps, step{indent: len(line) - len(name), name: name})
+	}
+
+	for i, s := range steps {
+		if !strings.HasPrefix(s.name, "Filter") {
+			continue
+		}
+		j := i + 1
+		for j < len(steps) && steps[j].indent > s.indent && strings.HasPrefix(steps[j].name, "Expression") {
+			j++
+		}
+		if j < len(steps) && steps[j].indent > s.indent && strings.HasPrefix(steps[j].name, "Join") {
+			return []entity.LintFinding{{
+				Message: "The EXPLAIN plan filters rows after the JOIN, both sides are joined in full first",
+			}}
+		}
+	}
+	return nil
+}
+
+// nullableColumns finds the Nullable columns the query uses
+func nullableColumns(q *query) []entity.LintFinding {
+	used := q.usedColumns()
+	return columnsOfType(q, used, func(typ string) bool {
+		return strings.HasPrefix(typ, "Nullable(") || strings.HasPrefix(typ, "LowCardinality(Nullable(")
+	}, "Nullable columns of %s used by the query: %s, each keeps a separate null map that is read with it")
+}
+
+// stringGroupKeys finds plain String columns the query groups by
+func stringGroupKeys(q *query) []entity.LintFinding {
+	grouped := q.clauseColumns("GROUP")
+	return columnsOfType(q, grouped, func(typ string) bool {
+		return typ == "String"
+	}, "Plain String columns of %s in GROUP BY: %s, every value is stored, hashed and compared in full")
+}
+
+// columnsOfType reports, per table the query reads, the columns in names whose type matches
+func columnsOfType(q *query, names map[string]bool, match func(typ string) bool, message string) []entity.LintFinding {
+	var findings []entity.LintFinding
+	for _, t := range q.readTables("FROM", "JOIN") {
+		var columns []string
+		for _, c := range t.def.Columns {
+			if names[c.Name] && match(c.Type) {
+				columns = append(columns, c.Name)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		findings = append(findings, entity.LintFinding{
+			Table:   t.FullName(),
+			Message: fmt.Sprintf(message, t.FullName(), strings.Join(columns, ", ")),
+		})
+	}
+	return findings
+}
+
+// joinUseNulls finds SETTINGS join_use_nulls = 1 on a query that joins
+func joinUseNulls(q *query) []entity.LintFinding {
+	if !q.uses("JOIN") {
+		return nil
+	}
+	for i, t := range q.tokens {
+		if !t.Is("join_use_nulls") || i+2 >= len(q.tokens) || q.tokens[i+1].Text != "=" {
+			continue
+		}
+		value := q.tokens[i+2]
+		if value.Text == "1" || value.Is("true") {
+			return []entity.LintFinding{{
+				Message: "join_use_nulls = 1 wraps the columns of the joined side in Nullable",
+			}}
+		}
+	}
+	return nil
+}
+
+// tableName returns the first table of the references, qualified with its database
+func tableName(refs chsql.References) string {
+	if len(refs.Tables) == 0 {
+		return ""
+	}
+	t := refs.Tables[0]
+	if t.Database == "" {
+		return t.Table
+	}
+	return t.Database + "." + t.Table
+}
@@ -42,16 +42,22 @@ func (p *Json) BuildSuccess(c *fiber.Ctx, data interface{}, message string, code
 }
 
 func (p *Json) BuildError(c *fiber.Ctx, err error) error {
+	httpCode, body := ErrorBody(err)
+	return c.Status(httpCode).JSON(body)
+}
+
+// ErrorBody returns the HTTP status and body BuildError answers err with, for responses that are written as a stream
+func ErrorBody(err error) (int, interface{}) {
 	unwrappedErr := errors.Unwrap(err)
 
 	if unwrappedErr != nil {
 		errorData := strings.Split(unwrappedErr.Error(), "XX: ")
 
 		if len(errorData) < 2 {
-			return c.Status(apperr.ErrGeneralInvalid().HTTPCode).
-				JSON(apperr.CustomError(err.Error(),
+			return apperr.ErrGeneralInvalid().HTTPCode,
+				apperr.CustomError(err.Error(),
 					entity.BAD_REQUEST_CODE,
-					http.StatusUnprocessableEntity))
+					http.StatusUnprocessableEntity)
 		}
 
 		errorCode := errorData[1]
@@ -62,21 +68,17 @@ func (p *Json) BuildError(c *fiber.Ctx, err error) error {
 			var errResponse []entity.ErrorResponse
 			json.Unmarshal([]byte(errorMessage), &errResponse)
 
-			return c.Status(apperr.ErrGeneralInvalid().HTTPCode).
-				JSON(
-					apperr.ErrInvalidPayload(errResponse),
-				)
+			return apperr.ErrGeneralInvalid().HTTPCode, apperr.ErrInvalidPayload(errResponse)
 		}
 	}
 
 	switch err := err.(type) {
 	case apperr.CustomErrorResponse:
-		httpCode := err.HTTPCode
-		return c.Status(httpCode).JSON(err)
+		return err.HTTPCode, err
 	default:
-		return c.Status(apperr.ErrGeneralInvalid().HTTPCode).
-			JSON(apperr.CustomError(err.Error(),
+		return apperr.ErrGeneralInvalid().HTTPCode,
+			apperr.CustomError(err.Error(),
 				entity.BAD_REQUEST_CODE,
-				http.StatusUnprocessableEntity))
+				http.StatusUnprocessableEntity)
 	}
 }
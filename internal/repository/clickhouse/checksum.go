@@ -0,0 +1,101 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// ResultChecksum hashes every row of the query result on the server, nothing but the checksum is sent back.
+// The xor of the row hashes ignores the row order, the wrapping sum catches rows repeated an even number of times.
+func (c *clientImpl) ResultChecksum(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.ResultChecksum, error) {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	queryID := opts.QueryID
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
+
+	checksumQuery := fmt.Sprintf("SELECT count(), groupBitXor(cityHash64(*)), sum(cityHash64(*)) FROM (\n%s\n)", subquery(query))
+
+	var (
+		rows     uint64
+		xor, sum uint64
+	)
+	if err := db.QueryRow(queryContext(ctx, queryID, opts), checksumQuery).Scan(&rows, &xor, &sum); err != nil {
+		return nil, err
+	}
+
+	return &entity.ResultChecksum{Rows: rows, Hash: fmt.Sprintf("%016x%016x", xor, sum)}, nil
+}
+
+// ResultColumns returns the names and types of the query result columns without reading any row
+func (c *clientImpl) ResultColumns(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) ([]entity.TableSchemaColumn, error) {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	queryID := opts.QueryID
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
+
+	rows, err := db.Query(queryContext(ctx, queryID, opts), fmt.Sprintf("SELECT * FROM (\n%s\n) LIMIT 0", subquery(query)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes := rows.ColumnTypes()
+	columns := make([]entity.TableSchemaColumn, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = entity.TableSchemaColumn{Name: ct.Name(), Type: ct.DatabaseTypeName()}
+	}
+	return columns, nil
+}
+
+// ResultDiff returns up to limit rows of the query result that the other query does not return.
+// Both results are matched column by column, they must have the same column types in the same order.
+func (c *clientImpl) ResultDiff(ctx context.Context, conn *entity.CHConnection, query, other string, limit int, opts entity.QueryOptions) ([]map[string]interface{}, error) {
+	diffQuery := fmt.Sprintf("SELECT * FROM (\n%s\n) EXCEPT SELECT * FROM (\n%s\n) LIMIT %d", subquery(query), subquery(other), limit)
+
+	stream, err := c.QueryRows(ctx, conn, diffQuery, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	columns := stream.Columns()
+	rows := make([]map[string]interface{}, 0)
+	for {
+		values, err := stream.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// subquery strips what would break the query once wrapped in FROM (...) or put after EXPLAIN. The parentheses go on
+// lines of their own, so a comment left in the query never swallows the closing one.
+func subquery(query string) string {
+	return chsql.TrimTail(query)
+}
@@ -2,11 +2,8 @@ package clickhouse
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"reflect"
-	"strings"
-	"sync"
+	"io"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -21,10 +18,19 @@ type ClickHouseClient interface {
 	GetTables(ctx context.Context, conn *entity.CHConnection) ([]entity.TableMeta, error)
 	GetCreateSQL(ctx context.Context, conn *entity.CHConnection, tableName string) (string, error)
 	GetServerInfo(ctx context.Context, conn *entity.CHConnection) (string, error)
+	TestConnection(ctx context.Context, conn *entity.CHConnection, result *entity.ConnectionTestResult)
 	GetSchema(ctx context.Context, conn *entity.CHConnection, tableName string) (*entity.TableSchema, error)
-	ExplainQuery(ctx context.Context, conn *entity.CHConnection, query string) (string, error)
-	ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryStats, error)
-	ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryResult, error)
+	ExplainQuery(ctx context.Context, conn *entity.CHConnection, kind, query string, opts entity.QueryOptions) (*entity.ExplainResult, error)
+	ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error)
+	ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryResult, error)
+	QueryRows(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (RowStream, error)
+	ExecStatement(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error)
+	ExportQuery(ctx context.Context, conn *entity.CHConnection, query string, format string, opts entity.QueryOptions) (io.ReadCloser, error)
+	KillQuery(ctx context.Context, conn *entity.CHConnection, queryID string) (bool, error)
+	DropCaches(ctx context.Context, conn *entity.CHConnection) error
+	ResultChecksum(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.ResultChecksum, error)
+	ResultColumns(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) ([]entity.TableSchemaColumn, error)
+	ResultDiff(ctx context.Context, conn *entity.CHConnection, query, other string, limit int, opts entity.QueryOptions) ([]map[string]interface{}, error)
 
 	// Configuration Menu Methods
 	GetClusterConfig(ctx context.Context, conn *entity.CHConnection) (*entity.ClusterInfo, error)
@@ -34,92 +40,30 @@ type ClickHouseClient interface {
 	GetStoragePolicies(ctx context.Context, conn *entity.CHConnection) ([]entity.StoragePolicy, []entity.Disk, error)
 	GetProcessStats(ctx context.Context, conn *entity.CHConnection) (*entity.ProcessStats, error)
 	GetLogConfig(ctx context.Context, conn *entity.CHConnection) (*entity.LogConfig, error)
-}
 
-type clientImpl struct {
-	conns map[string]driver.Conn
-	mu    sync.RWMutex
+	// Pool Management Methods
+	Invalidate(connectionID int64)
+	PoolStats() []entity.PoolStats
+	Close() error
 }
 
-func NewClickHouseClient() ClickHouseClient {
-	return &clientImpl{
-		conns: make(map[string]driver.Conn),
+func connOpenStrategy(strategy string) clickhouse.ConnOpenStrategy {
+	switch strategy {
+	case entity.ConnOpenRoundRobin:
+		return clickhouse.ConnOpenRoundRobin
+	case entity.ConnOpenRandom:
+		return clickhouse.ConnOpenRandom
+	default:
+		return clickhouse.ConnOpenInOrder
 	}
 }
 
-func (c *clientImpl) getConnection(conn *entity.CHConnection) (driver.Conn, error) {
-	// Create a unique key for the connection configuration
-	key := fmt.Sprintf("%v|%s|%d|%s|%s|%v|%s", conn.ID, conn.Host, conn.Port, conn.Username, conn.Database, conn.UseSSL, conn.Protocol)
-
-	c.mu.RLock()
-	existingConn, ok := c.conns[key]
-	c.mu.RUnlock()
-
-	if ok {
-		// Verify if connection is still alive
-		if err := existingConn.Ping(context.Background()); err == nil {
-			return existingConn, nil
-		}
-		// If dead, remove from pool and close (safely)
-		_ = existingConn.Close()
-		c.mu.Lock()
-		delete(c.conns, key)
-		c.mu.Unlock()
-	}
-
-	addr := fmt.Sprintf("%s:%d", conn.Host, conn.Port)
-
-	options := &clickhouse.Options{
-		Addr: []string{addr},
-		Auth: clickhouse.Auth{
-			Database: conn.Database,
-			Username: conn.Username,
-			Password: conn.Password,
-		},
-		Protocol: clickhouse.Native, // Default to Native
-		ClientInfo: clickhouse.ClientInfo{
-			Products: []struct {
-				Name    string
-				Version string
-			}{
-				{Name: "go-ch-manager", Version: "0.1"},
-			},
-		},
-		Debug: false,
-	}
-
-	if conn.Protocol == "http" {
-		options.Protocol = clickhouse.HTTP
-	}
-
-	if conn.UseSSL {
-		options.TLS = &tls.Config{
-			InsecureSkipVerify: true, // For now, allow self-signed or just skip verify to avoid complex cert loading UI. User just wants to toggle SSL.
-		}
-	}
-
-	newConn, err := clickhouse.Open(options)
-	if err != nil {
-		return nil, err
-	}
-
-	// Verify new connection immediately
-	if err := newConn.Ping(context.Background()); err != nil {
-		return nil, err
-	}
-
-	c.mu.Lock()
-	c.conns[key] = newConn
-	c.mu.Unlock()
-
-	return newConn, nil
-}
-
 func (c *clientImpl) Ping(ctx context.Context, conn *entity.CHConnection) error {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	if err := db.Ping(ctx); err != nil {
 		if exception, ok := err.(*clickhouse.Exception); ok {
@@ -131,10 +75,11 @@ func (c *clientImpl) Ping(ctx context.Context, conn *entity.CHConnection) error
 }
 
 func (c *clientImpl) GetDatabases(ctx context.Context, conn *entity.CHConnection) ([]string, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	var dbs []string
 	query := "SHOW DATABASES"
@@ -156,10 +101,11 @@ func (c *clientImpl) GetDatabases(ctx context.Context, conn *entity.CHConnection
 }
 
 func (c *clientImpl) GetTables(ctx context.Context, conn *entity.CHConnection) ([]entity.TableMeta, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	if conn.Database == "" {
 		conn.Database = "default"
@@ -190,10 +136,11 @@ func (c *clientImpl) GetTables(ctx context.Context, conn *entity.CHConnection) (
 }
 
 func (c *clientImpl) GetCreateSQL(ctx context.Context, conn *entity.CHConnection, tableName string) (string, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return "", err
 	}
+	defer release()
 
 	if conn.Database == "" {
 		conn.Database = "default"
@@ -222,10 +169,11 @@ func (c *clientImpl) GetCreateSQL(ctx context.Context, conn *entity.CHConnection
 }
 
 func (c *clientImpl) GetServerInfo(ctx context.Context, conn *entity.CHConnection) (string, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return "", err
 	}
+	defer release()
 
 	var version string
 	if err := db.QueryRow(ctx, "SELECT version()").Scan(&version); err != nil {
@@ -234,11 +182,38 @@ func (c *clientImpl) GetServerInfo(ctx context.Context, conn *entity.CHConnectio
 	return version, nil
 }
 
+// TestConnection dials conn on a connection of its own, never cached nor shared with the saved pools, and records
+// in result how far it got. The connection is closed before returning.
+func (c *clientImpl) TestConnection(ctx context.Context, conn *entity.CHConnection, result *entity.ConnectionTestResult) {
+	result.Stage = entity.ConnTestStagePing
+	start := time.Now()
+	db, err := c.dial(ctx, conn)
+	if err != nil {
+		if exception, ok := err.(*clickhouse.Exception); ok {
+			err = fmt.Errorf("clickhouse exception: [%d] %s", exception.Code, exception.Message)
+		}
+		result.Error = err.Error()
+		return
+	}
+	defer db.Close()
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	result.Stage = entity.ConnTestStageServerInfo
+	if err := db.QueryRow(ctx, "SELECT version()").Scan(&result.ServerVersion); err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	result.Stage = entity.ConnTestStageDone
+	result.Success = true
+}
+
 func (c *clientImpl) GetSchema(ctx context.Context, conn *entity.CHConnection, tableName string) (*entity.TableSchema, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	query := "SELECT name, type FROM system.columns WHERE table = ? AND database = ?"
 	if conn.Database == "" {
@@ -275,50 +250,17 @@ func (c *clientImpl) GetSchema(ctx context.Context, conn *entity.CHConnection, t
 	return schema, nil
 }
 
-func (c *clientImpl) ExplainQuery(ctx context.Context, conn *entity.CHConnection, query string) (string, error) {
-	db, err := c.getConnection(conn)
-	if err != nil {
-		return "", err
-	}
-
-	// Use EXPLAIN PLAN to get the query execution plan
-	explainQuery := "EXPLAIN PLAN " + query
-
-	rows, err := db.Query(ctx, explainQuery)
-	if err != nil {
-		return "", err
-	}
-	defer rows.Close()
-
-	var explainBuilder strings.Builder
-	for rows.Next() {
-		var line string
-		if err := rows.Scan(&line); err != nil {
-			return "", err
-		}
-		explainBuilder.WriteString(line)
-		explainBuilder.WriteString("\n")
-	}
-
-	if explainBuilder.String() == "" {
-		return "", fmt.Errorf("explain query returned no results")
-	}
-
-	return explainBuilder.String(), nil
-}
-
-func (c *clientImpl) ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryStats, error) {
-	db, err := c.getConnection(conn)
+func (c *clientImpl) ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error) {
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	queryID := uuid.New().String()
 
-	// Context with QueryID
-	ctxQuery := clickhouse.Context(ctx, clickhouse.WithQueryID(queryID))
-
-	clickhouse.WithQueryID(queryID)
+	// Context with QueryID and the query parameters
+	ctxQuery := queryContext(ctx, queryID, opts)
 
 	start := time.Now()
 	// Execute main query
@@ -329,156 +271,128 @@ func (c *clientImpl) ExecuteQueryWithStats(ctx context.Context, conn *entity.CHC
 	rows.Close() // Close immediately, we just want execution
 	duration := time.Since(start).Milliseconds()
 
-	// Flush logs to ensure data is written to system.query_log
-	if err := db.Exec(ctx, "SYSTEM FLUSH LOGS"); err != nil {
-		// Log warning? For now just proceed, might miss data if not flushed
-	}
-
-	// Fetch stats from system.query_log
-	// We wait a tiny bit? Ideally flush logs handles it.
-	statsQuery := `
-		SELECT
-			query_duration_ms,
-			read_rows,
-			read_bytes,
-			memory_usage,
-			ProfileEvents['SelectedParts'] as parts,
-			ProfileEvents['SelectedMarks'] as marks
-		FROM system.query_log
-		WHERE type = 'QueryFinish' 
-			AND query_id = ? 
-			AND query != 'SELECT displayName(), version(), revision(), timezone()'
-		LIMIT 1
-	`
-
-	var (
-		qDuration   uint64 // CH stores as UInt64
-		readRows    uint64
-		readBytes   uint64
-		memoryUsage uint64
-		parts       uint64
-		marks       uint64
-	)
-
-	// Retry loop? just once for now.
-	err = db.QueryRow(ctx, statsQuery, queryID).Scan(&qDuration, &readRows, &readBytes, &memoryUsage, &parts, &marks)
-	if err != nil {
-		// Fallback to client side timing if log not found immediately (async insert issue?)
-		// But user wants log data. Return partially empty or error?
-		// Let's return what we have (client side duration) and zeros if log fails.
-		return &entity.QueryStats{
-			ExecutionTimeMs: duration,
-			RowsRead:        0, // Unknown from log
-			BytesRead:       0,
-			MemoryPeak:      0,
-			PartsRead:       0,
-		}, nil
-	}
-
-	return &entity.QueryStats{
-		ExecutionTimeMs: int64(qDuration),
-		RowsRead:        readRows,
-		BytesRead:       readBytes,
-		MemoryPeak:      memoryUsage,
-		PartsRead:       parts,
-	}, nil
+	return c.fetchQueryStats(ctx, conn, db, queryID, duration), nil
 }
 
-func (c *clientImpl) ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryResult, error) {
-	db, err := c.getConnection(conn)
+func (c *clientImpl) ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryResult, error) {
+	stream, err := c.QueryRows(ctx, conn, query, opts)
 	if err != nil {
 		return nil, err
 	}
+	defer stream.Close()
 
-	queryID := uuid.New().String()
-	ctxQuery := clickhouse.Context(ctx, clickhouse.WithQueryID(queryID))
-
-	start := time.Now()
-	rows, err := db.Query(ctxQuery, query)
-	if err != nil {
-		return nil, err
-	}
-	// Get Columns
-	columns := rows.Columns()
+	columns := stream.Columns()
 	result := &entity.QueryResult{
+		QueryID: stream.QueryID(),
 		Columns: columns,
 		Rows:    make([]map[string]interface{}, 0),
 	}
 
-	// Dynamic Scan
-	columnTypes := rows.ColumnTypes()
-
-	for rows.Next() {
-		valuePtrs := make([]interface{}, len(columns))
-		for i, ct := range columnTypes {
-			// ClickHouse native driver requires scanning into specific types
-			// We use reflection to allocate a pointer to the type the driver expects
-			valuePtrs[i] = reflect.New(ct.ScanType()).Interface()
+	for {
+		values, err := stream.Next()
+		if err == io.EOF {
+			break
 		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			rows.Close() // Ensure closed on error
+		if err != nil {
 			return nil, err
 		}
 
-		rowMap := make(map[string]interface{})
+		rowMap := make(map[string]interface{}, len(columns))
 		for i, col := range columns {
-			// valuePtrs[i] is a pointer to the value, we need to dereference it
-			val := reflect.ValueOf(valuePtrs[i]).Elem().Interface()
-			rowMap[col] = val
+			rowMap[col] = values[i]
 		}
 		result.Rows = append(result.Rows, rowMap)
 	}
 
-	// Close rows explicitly to signal query finish to server for logging
-	rows.Close()
-	if err := rows.Err(); err != nil {
+	result.Truncated = stream.Truncated()
+	result.Stats = stream.Stats(ctx)
+	return result, nil
+}
+
+// ExecStatement runs a statement that returns no rows (DDL, INSERT ... VALUES, mutations) and loads its stats
+func (c *clientImpl) ExecStatement(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error) {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	duration := time.Since(start).Milliseconds()
+	queryID := opts.QueryID
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
 
-	// Flush logs
-	_ = db.Exec(ctx, "SYSTEM FLUSH LOGS")
-
-	// Get Stats
-	statsQuery := `
-		SELECT
-			query_duration_ms,
-			read_rows,
-			read_bytes,
-			memory_usage,
-			ProfileEvents['SelectedParts'] as parts,
-			ProfileEvents['SelectedMarks'] as marks
-		FROM system.query_log
-		WHERE type = 'QueryFinish' 
-			AND query_id = ? 
-			AND query != 'SELECT displayName(), version(), revision(), timezone()'
-		LIMIT 1
-	`
-	stats := &entity.QueryStats{
-		ExecutionTimeMs: duration, // Fallback
-	}
-
-	var (
-		qDuration   uint64
-		readRows    uint64
-		readBytes   uint64
-		memoryUsage uint64
-		parts       uint64
-		marks       uint64
-	)
-
-	err = db.QueryRow(ctx, statsQuery, queryID).Scan(&qDuration, &readRows, &readBytes, &memoryUsage, &parts, &marks)
-	if err == nil {
-		stats.ExecutionTimeMs = int64(qDuration)
-		stats.RowsRead = readRows
-		stats.BytesRead = readBytes
-		stats.MemoryPeak = memoryUsage
-		stats.PartsRead = parts
-		stats.MarksRead = marks
-	}
-
-	result.Stats = stats
-	return result, nil
+	start := time.Now()
+	if err := db.Exec(queryContext(ctx, queryID, opts), query); err != nil {
+		return nil, err
+	}
+
+	return c.fetchQueryStats(ctx, conn, db, queryID, time.Since(start).Milliseconds()), nil
+}
+
+// KillQuery asks the server to stop a running query, found is false when no replica runs it. When the pool spreads
+// queries over several replicas the one answering may not be the one running the query, the kill then goes
+// ON CLUSTER.
+func (c *clientImpl) KillQuery(ctx context.Context, conn *entity.CHConnection, queryID string) (bool, error) {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	if len(conn.Endpoints()) > 1 {
+		if cluster := c.serverCluster(ctx, conn, db); cluster != "" {
+			return killOnCluster(ctx, db, cluster, queryID)
+		}
+	}
+
+	// ASYNC returns right away, one row per query that was asked to stop
+	rows, err := db.Query(ctx, "KILL QUERY WHERE query_id = ? ASYNC", queryID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		found = true
+	}
+	return found, rows.Err()
+}
+
+// killOnCluster looks the query up on every replica, the DDL queue then delivers the kill in the background
+func killOnCluster(ctx context.Context, db driver.Conn, cluster, queryID string) (bool, error) {
+	var running uint64
+	lookupCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"skip_unavailable_shards": 1}))
+	err := db.QueryRow(lookupCtx, "SELECT count() FROM clusterAllReplicas(?, system.processes) WHERE query_id = ?", cluster, queryID).Scan(&running)
+	if err != nil {
+		return false, err
+	}
+	if running == 0 {
+		return false, nil
+	}
+
+	ddlCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"distributed_ddl_output_mode": "none"}))
+	if err := db.Exec(ddlCtx, "KILL QUERY ON CLUSTER ? WHERE query_id = ? ASYNC", cluster, queryID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DropCaches clears the caches a repeated query is served from, the OS page cache is out of reach.
+// The query cache only exists since 23.5, older servers refuse to drop it and are left as they are.
+func (c *clientImpl) DropCaches(ctx context.Context, conn *entity.CHConnection) error {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for _, statement := range []string{"SYSTEM DROP MARK CACHE", "SYSTEM DROP UNCOMPRESSED CACHE"} {
+		if err := db.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	_ = db.Exec(ctx, "SYSTEM DROP QUERY CACHE")
+	return nil
 }
@@ -20,12 +20,13 @@ func (c *clientImpl) GetClusterConfig(ctx context.Context, conn *entity.CHConnec
 		ReadWriteMode:   true, // Assuming RW for now
 	}
 
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		// Return partial info even if connection fails (so UI shows something)
 		// But also return error so caller knows
 		return info, err
 	}
+	defer release()
 
 	// Get basic version and time info
 	// Try most complete query first
@@ -80,10 +81,11 @@ func (c *clientImpl) GetClusterConfig(ctx context.Context, conn *entity.CHConnec
 }
 
 func (c *clientImpl) GetSettings(ctx context.Context, conn *entity.CHConnection) ([]entity.CHSetting, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	query := "SELECT name, value, changed, description, type, readonly FROM system.settings ORDER BY name"
 	rows, err := db.Query(ctx, query)
@@ -106,10 +108,11 @@ func (c *clientImpl) GetSettings(ctx context.Context, conn *entity.CHConnection)
 }
 
 func (c *clientImpl) GetUsers(ctx context.Context, conn *entity.CHConnection) ([]entity.CHUser, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	// Check if system.users exists (modern CH)
 	query := "SELECT name, id, storage, auth_type, host_ip, default_roles, default_database, profiles, quotas FROM system.users"
@@ -144,10 +147,11 @@ func (c *clientImpl) GetUsers(ctx context.Context, conn *entity.CHConnection) ([
 }
 
 func (c *clientImpl) GetRoles(ctx context.Context, conn *entity.CHConnection) ([]entity.CHRole, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	query := "SELECT name, id, storage FROM system.roles"
 	rows, err := db.Query(ctx, query)
@@ -168,10 +172,11 @@ func (c *clientImpl) GetRoles(ctx context.Context, conn *entity.CHConnection) ([
 }
 
 func (c *clientImpl) GetStoragePolicies(ctx context.Context, conn *entity.CHConnection) ([]entity.StoragePolicy, []entity.Disk, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer release()
 
 	// Helper to get Policies
 	var policies []entity.StoragePolicy
@@ -222,10 +227,11 @@ func (c *clientImpl) GetStoragePolicies(ctx context.Context, conn *entity.CHConn
 }
 
 func (c *clientImpl) GetProcessStats(ctx context.Context, conn *entity.CHConnection) (*entity.ProcessStats, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	stats := &entity.ProcessStats{}
 
@@ -254,10 +260,11 @@ func (c *clientImpl) GetProcessStats(ctx context.Context, conn *entity.CHConnect
 }
 
 func (c *clientImpl) GetLogConfig(ctx context.Context, conn *entity.CHConnection) (*entity.LogConfig, error) {
-	db, err := c.getConnection(conn)
+	db, release, err := c.getConnection(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
 	cfg := &entity.LogConfig{}
 
@@ -0,0 +1,70 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// explainStatements prefixes the query for each kind of EXPLAIN
+var explainStatements = map[string]string{
+	entity.ExplainKindPlan:      "EXPLAIN PLAN indexes = 1, actions = 1, json = 1 ",
+	entity.ExplainKindPipeline:  "EXPLAIN PIPELINE ",
+	entity.ExplainKindEstimate:  "EXPLAIN ESTIMATE ",
+	entity.ExplainKindSyntax:    "EXPLAIN SYNTAX ",
+	entity.ExplainKindQueryTree: "EXPLAIN QUERY TREE ",
+}
+
+// ExplainQuery runs one kind of EXPLAIN on the query. The plan comes back as the JSON the server prints,
+// the estimate as rows, the other kinds as their lines of text.
+func (c *clientImpl) ExplainQuery(ctx context.Context, conn *entity.CHConnection, kind, query string, opts entity.QueryOptions) (*entity.ExplainResult, error) {
+	statement, ok := explainStatements[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown explain kind %q", kind)
+	}
+
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := db.Query(queryContext(ctx, uuid.New().String(), opts), statement+subquery(query))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &entity.ExplainResult{Kind: kind}
+	var text strings.Builder
+	for rows.Next() {
+		if kind == entity.ExplainKindEstimate {
+			var e entity.ExplainEstimate
+			if err := rows.Scan(&e.Database, &e.Table, &e.Parts, &e.Rows, &e.Marks); err != nil {
+				return nil, err
+			}
+			result.Estimates = append(result.Estimates, e)
+			text.WriteString(fmt.Sprintf("%s.%s: %d parts, %d rows, %d marks\n", e.Database, e.Table, e.Parts, e.Rows, e.Marks))
+			continue
+		}
+
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		text.WriteString(line)
+		text.WriteString("\n")
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Text = text.String()
+	if result.Text == "" && kind != entity.ExplainKindEstimate {
+		return nil, fmt.Errorf("explain query returned no results")
+	}
+	return result, nil
+}
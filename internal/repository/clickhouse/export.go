@@ -0,0 +1,109 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// ExportQuery runs the query through the HTTP interface with the given output FORMAT and returns the raw file.
+// The native protocol only carries blocks, so this needs a connection using the http protocol.
+// A file cut short is unreadable, so a result over the limits of opts fails before anything is sent instead.
+// The caller must Close the body, which cancels the query when it is still running.
+func (c *clientImpl) ExportQuery(ctx context.Context, conn *entity.CHConnection, query string, format string, opts entity.QueryOptions) (io.ReadCloser, error) {
+	if conn.Protocol != "http" {
+		return nil, fmt.Errorf("%s export is written by the ClickHouse HTTP interface, switch the connection protocol to http", format)
+	}
+
+	password, err := c.cipher.Decrypt(conn.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt connection password: %w", err)
+	}
+
+	tlsConfig, err := BuildTLSConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	params := url.Values{}
+	for name, value := range opts.Settings {
+		params.Set(name, value)
+	}
+	queryID := opts.QueryID
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
+	params.Set("query_id", queryID)
+	if conn.Database != "" {
+		params.Set("database", conn.Database)
+	}
+	if opts.MaxResultRows > 0 {
+		params.Set("max_result_rows", strconv.FormatUint(opts.MaxResultRows, 10))
+	}
+	if opts.MaxResultBytes > 0 {
+		params.Set("max_result_bytes", strconv.FormatUint(opts.MaxResultBytes, 10))
+	}
+	if opts.MaxResultRows > 0 || opts.MaxResultBytes > 0 {
+		// The server holds the response until the query ends, so going over the limit is an error status
+		params.Set("result_overflow_mode", "throw")
+		params.Set("wait_end_of_query", "1")
+	}
+	for name, value := range opts.Parameters {
+		params.Set("param_"+name, value)
+	}
+
+	// The format of the file wins over any FORMAT the query ends with
+	body := chsql.TrimTail(query) + "\nFORMAT " + format
+
+	// A one-off transport, nothing is kept alive once the file is downloaded
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+
+	// Try the replicas in order, the first one answering runs the query
+	var lastErr error
+	for _, addr := range conn.Endpoints() {
+		endpoint := url.URL{Scheme: scheme, Host: addr, Path: "/", RawQuery: params.Encode()}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-ClickHouse-User", conn.Username)
+		req.Header.Set("X-ClickHouse-Key", password)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			// TOO_MANY_ROWS_OR_BYTES
+			if strings.HasPrefix(string(message), "Code: 396.") {
+				return nil, fmt.Errorf("the result is over the export limit, narrow the query to download it as %s", format)
+			}
+			return nil, fmt.Errorf("clickhouse exception: %s", strings.TrimSpace(string(message)))
+		}
+
+		return resp.Body, nil
+	}
+
+	return nil, lastErr
+}
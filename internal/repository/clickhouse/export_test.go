@@ -0,0 +1,79 @@
+package clickhouse_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportServer answers every export with the status and body given, the received query and URL parameters are
+// stored in got
+func exportServer(t *testing.T, status int, body string, got *url.Values, query *string) *entity.CHConnection {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent, _ := io.ReadAll(r.Body)
+		*query, *got = string(sent), r.URL.Query()
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return &entity.CHConnection{ID: 1, Host: host, Port: portNumber, Protocol: "http"}
+}
+
+func TestExportQuery(t *testing.T) {
+	cipher, err := secret.NewCipher([]byte("export-test"))
+	require.NoError(t, err)
+	client := clickhouse.NewClickHouseClient(cipher, 0)
+	defer client.Close()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "trailing comment", query: "SELECT 1;\n-- the last line is a comment"},
+		{name: "own FORMAT is replaced", query: "SELECT 1 FORMAT JSONEachRow;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params url.Values
+			var query string
+			conn := exportServer(t, http.StatusOK, "PAR1", &params, &query)
+
+			body, err := client.ExportQuery(context.Background(), conn, tt.query, "Parquet", entity.QueryOptions{MaxResultBytes: 1024})
+			require.NoError(t, err)
+			defer body.Close()
+
+			assert.Equal(t, "SELECT 1\nFORMAT Parquet", query)
+			assert.Equal(t, "throw", params.Get("result_overflow_mode"))
+			assert.Equal(t, "1", params.Get("wait_end_of_query"))
+		})
+	}
+}
+
+func TestExportQueryOverLimit(t *testing.T) {
+	cipher, err := secret.NewCipher([]byte("export-test"))
+	require.NoError(t, err)
+	client := clickhouse.NewClickHouseClient(cipher, 0)
+	defer client.Close()
+
+	var params url.Values
+	var query string
+	conn := exportServer(t, http.StatusInternalServerError,
+		"Code: 396. DB::Exception: Limit for result exceeded, max bytes: 1.00 KiB, current bytes: 8.00 KiB. (TOO_MANY_ROWS_OR_BYTES)", &params, &query)
+
+	_, err = client.ExportQuery(context.Background(), conn, "SELECT * FROM numbers(1000)", "Parquet", entity.QueryOptions{MaxResultBytes: 1024})
+	assert.EqualError(t, err, "the result is over the export limit, narrow the query to download it as Parquet")
+}
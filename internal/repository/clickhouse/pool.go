@@ -0,0 +1,331 @@
+package clickhouse
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+)
+
+// A cached pool used within this window is trusted without a health ping
+const pingAfterIdle = 30 * time.Second
+
+type poolEntry struct {
+	conn         driver.Conn
+	connectionID int64
+	endpoints    []string
+	createdAt    time.Time
+	lastUsed     time.Time
+}
+
+type clientImpl struct {
+	conns     map[string]*poolEntry
+	clusters  map[int64]string // cluster of the servers, for query_log lookups and kills, per saved connection
+	queryLogs map[int64]bool   // whether the servers log queries, per saved connection
+	mu        sync.Mutex
+	cipher    secret.Cipher
+	idleTTL   time.Duration
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClickHouseClient creates a client that keeps one driver pool per saved connection and database.
+// Pools unused for longer than idleTTL are closed in the background, 0 keeps them forever.
+func NewClickHouseClient(cipher secret.Cipher, idleTTL time.Duration) ClickHouseClient {
+	c := &clientImpl{
+		conns:     make(map[string]*poolEntry),
+		clusters:  make(map[int64]string),
+		queryLogs: make(map[int64]bool),
+		cipher:    cipher,
+		idleTTL:   idleTTL,
+		stop:      make(chan struct{}),
+	}
+
+	if idleTTL > 0 {
+		go c.evictIdle()
+	}
+
+	return c
+}
+
+// connectionKey tells pools apart by every setting they were dialed with, the password only as a hash. A saved
+// connection keeps one pool per database it is browsed in, they are all closed together by Invalidate.
+func connectionKey(conn *entity.CHConnection) string {
+	return fmt.Sprintf("%v|%s|%d|%s|%s|%s|%x|%s|%v|%s|%s|%s|%s|%s|%v|%d|%d", conn.ID, conn.Host, conn.Port, conn.Hosts, conn.ConnOpenStrategy, conn.Username, sha256.Sum256([]byte(conn.Password)), conn.Database, conn.UseSSL, conn.Protocol,
+		conn.TLSCACert, conn.TLSClientCert, conn.TLSClientKey, conn.TLSServerName, conn.TLSSkipVerify, conn.MaxOpenConns, conn.MaxIdleConns)
+}
+
+// getConnection returns the pool of conn and a release func to call once done with it. Pools of saved connections are
+// cached, a connection without ID (test, create, import) gets a pool of its own that release closes.
+func (c *clientImpl) getConnection(ctx context.Context, conn *entity.CHConnection) (driver.Conn, func(), error) {
+	if conn.ID == 0 {
+		db, err := c.dial(ctx, conn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { _ = db.Close() }, nil
+	}
+
+	key := connectionKey(conn)
+
+	c.mu.Lock()
+	entry, ok := c.conns[key]
+	c.mu.Unlock()
+
+	if ok {
+		if time.Since(c.lastUsed(entry)) < pingAfterIdle {
+			c.touch(entry)
+			return entry.conn, keepPool, nil
+		}
+
+		// Verify the pool is still alive after sitting idle for a while
+		err := entry.conn.Ping(ctx)
+		if err == nil {
+			c.touch(entry)
+			return entry.conn, keepPool, nil
+		}
+		// The caller gave up, that says nothing about the pool itself
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		c.remove(key, entry)
+	}
+
+	newConn, err := c.dial(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	entry = &poolEntry{
+		conn:         newConn,
+		connectionID: conn.ID,
+		endpoints:    conn.Endpoints(),
+		createdAt:    now,
+		lastUsed:     now,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another request opened the same pool meanwhile, keep theirs
+	if existing, ok := c.conns[key]; ok {
+		_ = newConn.Close()
+		existing.lastUsed = now
+		return existing.conn, keepPool, nil
+	}
+
+	c.conns[key] = entry
+	return newConn, keepPool, nil
+}
+
+// keepPool is the release of a cached pool, it stays open for the next call
+func keepPool() {}
+
+// dial opens a pool for conn and checks it answers
+func (c *clientImpl) dial(ctx context.Context, conn *entity.CHConnection) (driver.Conn, error) {
+	db, err := c.open(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify new connection immediately
+	if err := db.Ping(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (c *clientImpl) open(conn *entity.CHConnection) (driver.Conn, error) {
+	addrs := conn.Endpoints()
+
+	// Password is stored encrypted, this is the only place it gets decrypted
+	password, err := c.cipher.Decrypt(conn.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt connection password: %w", err)
+	}
+
+	options := &clickhouse.Options{
+		Addr:             addrs,
+		ConnOpenStrategy: connOpenStrategy(conn.ConnOpenStrategy),
+		Auth: clickhouse.Auth{
+			Database: conn.Database,
+			Username: conn.Username,
+			Password: password,
+		},
+		Protocol: clickhouse.Native, // Default to Native
+		ClientInfo: clickhouse.ClientInfo{
+			Products: []struct {
+				Name    string
+				Version string
+			}{
+				{Name: "go-ch-manager", Version: "0.1"},
+			},
+		},
+		// Zero values fall back to the driver defaults (5 idle, idle + 5 open)
+		MaxOpenConns: conn.MaxOpenConns,
+		MaxIdleConns: conn.MaxIdleConns,
+		Debug:        false,
+	}
+
+	if conn.Protocol == "http" {
+		options.Protocol = clickhouse.HTTP
+	}
+
+	// The driver never keeps more idle connections than it may open
+	if options.MaxOpenConns > 0 && options.MaxIdleConns > options.MaxOpenConns {
+		options.MaxIdleConns = options.MaxOpenConns
+	}
+
+	// With several replicas fail over quickly instead of waiting the default 30s per dead host
+	if len(addrs) > 1 {
+		options.DialTimeout = 5 * time.Second
+	}
+
+	tlsConfig, err := BuildTLSConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	options.TLS = tlsConfig
+
+	return clickhouse.Open(options)
+}
+
+func (c *clientImpl) lastUsed(entry *poolEntry) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return entry.lastUsed
+}
+
+func (c *clientImpl) touch(entry *poolEntry) {
+	c.mu.Lock()
+	entry.lastUsed = time.Now()
+	c.mu.Unlock()
+}
+
+// remove closes the pool stored under key, unless it was already replaced
+func (c *clientImpl) remove(key string, entry *poolEntry) {
+	c.mu.Lock()
+	if current, ok := c.conns[key]; ok && current == entry {
+		delete(c.conns, key)
+	}
+	c.mu.Unlock()
+
+	_ = entry.conn.Close()
+}
+
+func (c *clientImpl) evictIdle() {
+	interval := c.idleTTL / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			var idle []*poolEntry
+
+			c.mu.Lock()
+			for key, entry := range c.conns {
+				if time.Since(entry.lastUsed) > c.idleTTL {
+					idle = append(idle, entry)
+					delete(c.conns, key)
+				}
+			}
+			c.mu.Unlock()
+
+			for _, entry := range idle {
+				_ = entry.conn.Close()
+			}
+		}
+	}
+}
+
+// Invalidate closes every pool opened for the saved connection, the next call dials again
+func (c *clientImpl) Invalidate(connectionID int64) {
+	var stale []*poolEntry
+
+	c.mu.Lock()
+	delete(c.clusters, connectionID)
+	delete(c.queryLogs, connectionID)
+	for key, entry := range c.conns {
+		if entry.connectionID == connectionID {
+			stale = append(stale, entry)
+			delete(c.conns, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, entry := range stale {
+		_ = entry.conn.Close()
+	}
+}
+
+func (c *clientImpl) PoolStats() []entity.PoolStats {
+	now := time.Now()
+
+	c.mu.Lock()
+	stats := make([]entity.PoolStats, 0, len(c.conns))
+	for _, entry := range c.conns {
+		driverStats := entry.conn.Stats()
+		stats = append(stats, entity.PoolStats{
+			ConnectionID: entry.connectionID,
+			Endpoints:    entry.endpoints,
+			Open:         driverStats.Open,
+			Idle:         driverStats.Idle,
+			MaxOpenConns: driverStats.MaxOpenConns,
+			MaxIdleConns: driverStats.MaxIdleConns,
+			CreatedAt:    entry.createdAt,
+			LastUsedAt:   entry.lastUsed,
+			AgeSeconds:   int64(now.Sub(entry.createdAt).Seconds()),
+			IdleSeconds:  int64(now.Sub(entry.lastUsed).Seconds()),
+		})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ConnectionID != stats[j].ConnectionID {
+			return stats[i].ConnectionID < stats[j].ConnectionID
+		}
+		return stats[i].CreatedAt.Before(stats[j].CreatedAt)
+	})
+
+	return stats
+}
+
+// Close stops the idle eviction and closes every pool
+func (c *clientImpl) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+
+	c.mu.Lock()
+	entries := c.conns
+	c.conns = make(map[string]*poolEntry)
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, entry := range entries {
+		if err := entry.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
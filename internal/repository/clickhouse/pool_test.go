@@ -0,0 +1,45 @@
+package clickhouse_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+	"github.com/rahmatrdn/go-ch-manager/tests/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolPerDatabase(t *testing.T) {
+	server := fixture.NewClickHouseServer(t)
+	cipher, err := secret.NewCipher([]byte("pool-test"))
+	require.NoError(t, err)
+
+	client := clickhouse.NewClickHouseClient(cipher, 0)
+	defer client.Close()
+
+	// Browsing another database opens a pool next to the main one instead of replacing it
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.Ping(ctx, server.Connection(1, "analytics")))
+		require.NoError(t, client.Ping(ctx, server.Connection(1, "logs")))
+	}
+
+	stats := client.PoolStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, int64(1), stats[0].ConnectionID)
+	assert.Equal(t, int64(1), stats[1].ConnectionID)
+
+	dialed := map[string]int{}
+	for _, req := range server.Requests() {
+		if strings.Contains(req.Query, "displayName()") {
+			dialed[req.Database]++
+		}
+	}
+	assert.Equal(t, map[string]int{"analytics": 1, "logs": 1}, dialed)
+
+	client.Invalidate(1)
+	assert.Empty(t, client.PoolStats())
+}
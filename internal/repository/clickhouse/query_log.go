@@ -0,0 +1,231 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+const (
+	// The query_log entry is looked up that many times, waiting twice as long after each miss. The logs were flushed
+	// before the first lookup, the retries only cover a flush racing the end of the query.
+	statsAttempts = 3
+	statsBackoff  = 50 * time.Millisecond
+)
+
+// errNotLogged is returned while the query_log entry of the query is not written yet
+var errNotLogged = errors.New("query not found in system.query_log")
+
+// fetchQueryStats reads the query_log entries of a finished query and of the secondary queries it sent to other
+// servers. The local log is read first, the whole cluster only when the query went through a Distributed table or
+// sent secondary queries, or when it was not logged locally while the pool spreads queries over several replicas.
+// When the entry cannot be found, or the server does not log queries, the stats only hold the client side duration and
+// are flagged as unavailable.
+func (c *clientImpl) fetchQueryStats(ctx context.Context, conn *entity.CHConnection, db driver.Conn, queryID string, duration int64) *entity.QueryStats {
+	if !c.logsQueries(ctx, conn, db) {
+		return &entity.QueryStats{ExecutionTimeMs: duration, Unavailable: true}
+	}
+
+	stats, err := lookupQueryLog(ctx, db, "", queryID)
+	if err == nil && stats.SecondaryQueries == 0 && !stats.distributed {
+		return stats.QueryStats
+	}
+	if err != nil && len(conn.Endpoints()) < 2 {
+		return &entity.QueryStats{ExecutionTimeMs: duration, Unavailable: true}
+	}
+
+	cluster := c.serverCluster(ctx, conn, db)
+	if cluster != "" {
+		if clusterStats, clusterErr := lookupQueryLog(ctx, db, cluster, queryID); clusterErr == nil {
+			return clusterStats.QueryStats
+		}
+	}
+	if err == nil {
+		// A replica refused the lookup, the local entry is still right about the query itself
+		return stats.QueryStats
+	}
+	return &entity.QueryStats{ExecutionTimeMs: duration, Unavailable: true}
+}
+
+// lookupQueryLog waits for the query_log entry of the query, in the local log or across the replicas of cluster.
+// The replicas are asked to flush once, the DDL queue does it in the background.
+func lookupQueryLog(ctx context.Context, db driver.Conn, cluster string, queryID string) (*queryLogEntry, error) {
+	onCluster := cluster
+
+	backoff := statsBackoff
+	for attempt := 1; ; attempt++ {
+		flushLogs(ctx, db, onCluster)
+		onCluster = ""
+
+		stats, err := queryLogStats(ctx, db, cluster, queryID)
+		if err == nil || !errors.Is(err, errNotLogged) || attempt >= statsAttempts {
+			return stats, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// serverCluster returns the largest cluster the server belongs to, empty on a single node. It is looked up once per
+// saved connection, Invalidate forgets it along with the pools.
+func (c *clientImpl) serverCluster(ctx context.Context, conn *entity.CHConnection, db driver.Conn) string {
+	c.mu.Lock()
+	cluster, ok := c.clusters[conn.ID]
+	c.mu.Unlock()
+	if ok {
+		return cluster
+	}
+
+	err := db.QueryRow(ctx, `
+		SELECT cluster
+		FROM system.clusters
+		GROUP BY cluster
+		HAVING countIf(is_local) > 0 AND count() > 1
+		ORDER BY count() DESC, cluster
+		LIMIT 1
+	`).Scan(&cluster)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ""
+	}
+
+	if conn.ID != 0 {
+		c.mu.Lock()
+		c.clusters[conn.ID] = cluster
+		c.mu.Unlock()
+	}
+	return cluster
+}
+
+// logsQueries reports whether the server writes system.query_log for the queries of the connection. It is looked up
+// once per saved connection like serverCluster, and taken as true when the lookup fails.
+func (c *clientImpl) logsQueries(ctx context.Context, conn *entity.CHConnection, db driver.Conn) bool {
+	c.mu.Lock()
+	logged, ok := c.queryLogs[conn.ID]
+	c.mu.Unlock()
+	if ok {
+		return logged
+	}
+
+	var enabled uint8
+	err := db.QueryRow(ctx, `
+		SELECT (SELECT value FROM system.settings WHERE name = 'log_queries') = '1'
+			AND (SELECT count() FROM system.tables WHERE database = 'system' AND name = 'query_log') > 0
+	`).Scan(&enabled)
+	if err != nil {
+		return true
+	}
+
+	if conn.ID != 0 {
+		c.mu.Lock()
+		c.queryLogs[conn.ID] = enabled == 1
+		c.mu.Unlock()
+	}
+	return enabled == 1
+}
+
+// flushLogs writes the pending query_log entries, also on every replica of cluster without waiting for them
+func flushLogs(ctx context.Context, db driver.Conn, cluster string) {
+	if cluster != "" {
+		ddlCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"distributed_ddl_output_mode": "none"}))
+		_ = db.Exec(ddlCtx, "SYSTEM FLUSH LOGS ON CLUSTER ?", cluster)
+	}
+	_ = db.Exec(ctx, "SYSTEM FLUSH LOGS")
+}
+
+// queryLogEntry is the stats of a query and whether it read a Distributed table
+type queryLogEntry struct {
+	*entity.QueryStats
+	distributed bool
+}
+
+// queryLogStats aggregates the query with its secondary queries: rows and bytes as the initial query counted them,
+// ProfileEvents and threads summed over every server, the memory peak of the busiest one
+func queryLogStats(ctx context.Context, db driver.Conn, cluster string, queryID string) (*queryLogEntry, error) {
+	source := "system.query_log"
+	args := []any{queryID}
+	if cluster != "" {
+		source = "clusterAllReplicas(?, system.query_log)"
+		args = []any{cluster, queryID}
+		// A replica that is down must not hide the others
+		ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"skip_unavailable_shards": 1}))
+	}
+
+	statsQuery := `
+		SELECT
+			countIf(is_initial_query) as found,
+			countIf(NOT is_initial_query) as secondary,
+			maxIf(query_duration_ms, is_initial_query),
+			maxIf(read_rows, is_initial_query),
+			maxIf(read_bytes, is_initial_query),
+			maxIf(result_rows, is_initial_query),
+			max(memory_usage),
+			sum(length(thread_ids)) as threads,
+			sumMap(ProfileEvents),
+			anyIf(hostname, is_initial_query) as served_by,
+			maxIf(hasAny(tables, (SELECT groupArray(concat(database, '.', name)) FROM system.tables WHERE engine = 'Distributed')), is_initial_query) as distributed
+		FROM ` + source + `
+		WHERE type = 'QueryFinish'
+			AND initial_query_id = ?
+			AND query != 'SELECT displayName(), version(), revision(), timezone()'
+	`
+
+	var (
+		found         uint64
+		secondary     uint64
+		qDuration     uint64
+		readRows      uint64
+		readBytes     uint64
+		resultRows    uint64
+		memoryUsage   uint64
+		threads       uint64
+		profileEvents map[string]uint64
+		servedBy      string
+		distributed   uint8
+	)
+
+	err := db.QueryRow(ctx, statsQuery, args...).Scan(&found, &secondary, &qDuration, &readRows, &readBytes, &resultRows, &memoryUsage, &threads, &profileEvents, &servedBy, &distributed)
+	if err != nil {
+		return nil, err
+	}
+	if found == 0 {
+		return nil, errNotLogged
+	}
+
+	stats := &entity.QueryStats{
+		ExecutionTimeMs:  int64(qDuration),
+		RowsRead:         readRows,
+		BytesRead:        readBytes,
+		ResultRows:       resultRows,
+		MemoryPeak:       memoryUsage,
+		Threads:          threads,
+		SecondaryQueries: secondary,
+		ServedBy:         servedBy,
+	}
+	applyProfileEvents(stats, profileEvents)
+	return &queryLogEntry{QueryStats: stats, distributed: distributed == 1}, nil
+}
+
+// applyProfileEvents copies the counters QueryStats has a field for, a counter the query never hit is absent
+func applyProfileEvents(stats *entity.QueryStats, events map[string]uint64) {
+	stats.ProfileEvents = events
+	stats.PartsRead = events["SelectedParts"]
+	stats.MarksRead = events["SelectedMarks"]
+	stats.UserTimeUs = events["UserTimeMicroseconds"]
+	stats.SystemTimeUs = events["SystemTimeMicroseconds"]
+	stats.NetworkReceiveBytes = events["NetworkReceiveBytes"]
+	stats.NetworkSendBytes = events["NetworkSendBytes"]
+	stats.MarkCacheHits = events["MarkCacheHits"]
+	stats.MarkCacheMisses = events["MarkCacheMisses"]
+	stats.QueryCacheHits = events["QueryCacheHits"]
+	stats.QueryCacheMisses = events["QueryCacheMisses"]
+}
@@ -0,0 +1,205 @@
+package clickhouse
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// RowStream reads a result row by row, only the current block is held in memory
+type RowStream interface {
+	QueryID() string
+	Columns() []string
+	// Next returns the next row, io.EOF once the result is exhausted
+	Next() ([]interface{}, error)
+	RowCount() uint64
+	// Truncated reports whether the result stopped at the row/byte limits
+	Truncated() bool
+	Close() error
+	// Stats closes the stream and loads the query_log entry of the query
+	Stats(ctx context.Context) *entity.QueryStats
+}
+
+type rowStream struct {
+	client    *clientImpl
+	conn      *entity.CHConnection
+	db        driver.Conn
+	release   func()
+	rows      driver.Rows
+	queryID   string
+	columns   []string
+	scanTypes []reflect.Type
+	opts      entity.QueryOptions
+
+	start    time.Time
+	duration int64
+	count    uint64
+	closed   bool
+	released bool
+}
+
+// queryContext tags the query with an ID and applies the result limits and parameters of opts
+func queryContext(ctx context.Context, queryID string, opts entity.QueryOptions) context.Context {
+	settings := clickhouse.Settings{}
+	for name, value := range opts.Settings {
+		settings[name] = value
+	}
+	if opts.MaxResultRows > 0 {
+		settings["max_result_rows"] = opts.MaxResultRows
+	}
+	if opts.MaxResultBytes > 0 {
+		settings["max_result_bytes"] = opts.MaxResultBytes
+	}
+	if opts.MaxResultRows > 0 || opts.MaxResultBytes > 0 {
+		settings["result_overflow_mode"] = "break"
+	}
+	if opts.MaxRowsToRead > 0 {
+		settings["max_rows_to_read"] = opts.MaxRowsToRead
+		settings["read_overflow_mode"] = "break"
+	}
+	if opts.MaxExecutionTime > 0 {
+		settings["max_execution_time"] = opts.MaxExecutionTime
+		settings["timeout_overflow_mode"] = "break"
+	}
+	if opts.ReadOnly {
+		// Checked against the settings the query started with, so it goes along with the limits above
+		settings["readonly"] = 1
+	}
+
+	options := []clickhouse.QueryOption{clickhouse.WithQueryID(queryID), clickhouse.WithSettings(settings)}
+	if len(opts.Parameters) > 0 {
+		options = append(options, clickhouse.WithParameters(clickhouse.Parameters(opts.Parameters)))
+	}
+
+	return clickhouse.Context(ctx, options...)
+}
+
+// QueryRows starts the query and returns as soon as the first block is available.
+// The query runs as long as ctx does, the caller must Close the stream.
+func (c *clientImpl) QueryRows(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (RowStream, error) {
+	db, release, err := c.getConnection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	queryID := opts.QueryID
+	if queryID == "" {
+		queryID = uuid.New().String()
+	}
+
+	start := time.Now()
+	rows, err := db.Query(queryContext(ctx, queryID, opts), query)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	columnTypes := rows.ColumnTypes()
+	scanTypes := make([]reflect.Type, len(columnTypes))
+	for i, ct := range columnTypes {
+		// ClickHouse native driver requires scanning into specific types
+		scanTypes[i] = ct.ScanType()
+	}
+
+	return &rowStream{
+		client:    c,
+		conn:      conn,
+		db:        db,
+		release:   release,
+		rows:      rows,
+		queryID:   queryID,
+		columns:   rows.Columns(),
+		scanTypes: scanTypes,
+		opts:      opts,
+		start:     start,
+	}, nil
+}
+
+func (s *rowStream) QueryID() string {
+	return s.queryID
+}
+
+func (s *rowStream) Columns() []string {
+	return s.columns
+}
+
+func (s *rowStream) RowCount() uint64 {
+	return s.count
+}
+
+func (s *rowStream) Truncated() bool {
+	// With result_overflow_mode = 'break' the server stops on a block boundary, at or after the limit
+	return s.opts.MaxResultRows > 0 && s.count >= s.opts.MaxResultRows
+}
+
+func (s *rowStream) Next() ([]interface{}, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	if !s.rows.Next() {
+		err := s.rows.Err()
+		s.closeRows()
+		if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	valuePtrs := make([]interface{}, len(s.scanTypes))
+	for i, t := range s.scanTypes {
+		// We use reflection to allocate a pointer to the type the driver expects
+		valuePtrs[i] = reflect.New(t).Interface()
+	}
+
+	if err := s.rows.Scan(valuePtrs...); err != nil {
+		s.closeRows()
+		return nil, err
+	}
+
+	values := make([]interface{}, len(valuePtrs))
+	for i := range valuePtrs {
+		// valuePtrs[i] is a pointer to the value, we need to dereference it
+		values[i] = reflect.ValueOf(valuePtrs[i]).Elem().Interface()
+	}
+
+	s.count++
+	return values, nil
+}
+
+func (s *rowStream) Close() error {
+	err := s.closeRows()
+	s.releaseConn()
+	return err
+}
+
+func (s *rowStream) Stats(ctx context.Context) *entity.QueryStats {
+	s.closeRows()
+	defer s.releaseConn()
+	return s.client.fetchQueryStats(ctx, s.conn, s.db, s.queryID, s.duration)
+}
+
+func (s *rowStream) closeRows() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.duration = time.Since(s.start).Milliseconds()
+
+	// Close rows explicitly to signal query finish to server for logging
+	return s.rows.Close()
+}
+
+// releaseConn hands the connection back once the stream is done with it, an unsaved one is closed
+func (s *rowStream) releaseConn() {
+	if !s.released {
+		s.released = true
+		s.release()
+	}
+}
@@ -0,0 +1,53 @@
+package clickhouse
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// tls.go builds the per connection TLS configuration (CA bundle, client certs, verify toggle)
+
+// BuildTLSConfig returns nil when the connection does not use SSL.
+// Cert material is read from the paths stored on the connection, unreadable files are reported as errors.
+func BuildTLSConfig(conn *entity.CHConnection) (*tls.Config, error) {
+	if !conn.UseSSL {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         conn.TLSServerName,
+		InsecureSkipVerify: conn.TLSSkipVerify,
+	}
+
+	if conn.TLSCACert != "" {
+		caPEM, err := os.ReadFile(conn.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate %q: %w", conn.TLSCACert, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA certificate %q does not contain any valid PEM certificate", conn.TLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Client cert and key only make sense together (mutual TLS)
+	if conn.TLSClientCert != "" || conn.TLSClientKey != "" {
+		if conn.TLSClientCert == "" || conn.TLSClientKey == "" {
+			return nil, fmt.Errorf("both client certificate and client key are required for mutual TLS")
+		}
+
+		cert, err := tls.LoadX509KeyPair(conn.TLSClientCert, conn.TLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,109 @@
+package clickhouse_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate and its key as PEM files into dir
+func writeCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	caFile, _ := writeCert(t, dir, "ca")
+	clientCert, clientKey := writeCert(t, dir, "client")
+
+	config, err := clickhouse.BuildTLSConfig(&entity.CHConnection{UseSSL: false, TLSCACert: filepath.Join(dir, "missing.pem")})
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = clickhouse.BuildTLSConfig(&entity.CHConnection{
+		UseSSL:        true,
+		TLSCACert:     caFile,
+		TLSClientCert: clientCert,
+		TLSClientKey:  clientKey,
+		TLSServerName: "ch.internal",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ch.internal", config.ServerName)
+	assert.False(t, config.InsecureSkipVerify)
+	assert.NotNil(t, config.RootCAs)
+	assert.Len(t, config.Certificates, 1)
+}
+
+func TestBuildTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey := writeCert(t, dir, "client")
+	_, otherKey := writeCert(t, dir, "other")
+
+	testcases := []struct {
+		name    string
+		conn    entity.CHConnection
+		wantErr string
+	}{
+		{
+			name:    "Missing CA",
+			conn:    entity.CHConnection{TLSCACert: filepath.Join(dir, "missing.pem")},
+			wantErr: "unable to read CA certificate",
+		},
+		{
+			name:    "CA Without Certificate",
+			conn:    entity.CHConnection{TLSCACert: clientKey},
+			wantErr: "does not contain any valid PEM certificate",
+		},
+		{
+			name:    "Key Of Another Certificate",
+			conn:    entity.CHConnection{TLSClientCert: clientCert, TLSClientKey: otherKey},
+			wantErr: "unable to load client certificate/key",
+		},
+		{
+			name:    "Certificate Without Key",
+			conn:    entity.CHConnection{TLSClientCert: clientCert},
+			wantErr: "both client certificate and client key are required",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conn.UseSSL = true
+			_, err := clickhouse.BuildTLSConfig(&tt.conn)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
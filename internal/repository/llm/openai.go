@@ -0,0 +1,195 @@
+// Package llm talks to language models over the OpenAI chat completions API, which OpenAI and most local
+// servers (Ollama, vLLM, llama.cpp, LM Studio) speak.
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// OpenAIAnalyzer sends prompts to the /chat/completions endpoint under BaseURL and streams the answer back
+type OpenAIAnalyzer struct {
+	baseURL   string
+	apiKey    string
+	model     string
+	maxTokens int
+	client    *http.Client
+}
+
+// NewOpenAIAnalyzer returns an analyzer for the API at baseURL (https://api.openai.com/v1 for OpenAI). The key is
+// left out of the requests when empty, the answer is cut at maxTokens and the whole request at timeout.
+func NewOpenAIAnalyzer(baseURL, apiKey, model string, maxTokens int, timeout time.Duration) *OpenAIAnalyzer {
+	return &OpenAIAnalyzer{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		model:     model,
+		maxTokens: maxTokens,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Model names the model answering the prompts
+func (a *OpenAIAnalyzer) Model() string {
+	return a.model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// streamOptions asks for the token usage in the last event of a streamed answer
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatResponse is a whole answer, or with Delta set one event of a streamed answer
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		Delta        chatMessage `json:"delta"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Analyze sends the system and user prompt and passes each piece of the answer to onDelta as it arrives.
+// Servers that ignore stream answer in one piece, which is passed on whole.
+func (a *OpenAIAnalyzer) Analyze(ctx context.Context, system, prompt string, onDelta func(string)) (*entity.AICompletion, error) {
+	body := chatRequest{
+		Model: a.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:     a.maxTokens,
+		Stream:        true,
+		StreamOptions: &streamOptions{IncludeUsage: true},
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	if onDelta == nil {
+		onDelta = func(string) {}
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readWhole(resp.Body, onDelta)
+	}
+	return readStream(resp.Body, onDelta)
+}
+
+// readStream reads the server-sent events of a streamed answer until data: [DONE]
+func readStream(r io.Reader, onDelta func(string)) (*entity.AICompletion, error) {
+	completion := &entity.AICompletion{}
+	var content strings.Builder
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var event chatResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to read the LLM answer: %w", err)
+		}
+		for _, choice := range event.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				onDelta(choice.Delta.Content)
+			}
+			completion.Truncated = completion.Truncated || choice.FinishReason == "length"
+		}
+		if event.Usage != nil {
+			completion.PromptTokens, completion.CompletionTokens = event.Usage.PromptTokens, event.Usage.CompletionTokens
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the LLM answer: %w", err)
+	}
+
+	completion.Content = content.String()
+	return completion, nil
+}
+
+func readWhole(r io.Reader, onDelta func(string)) (*entity.AICompletion, error) {
+	var answer chatResponse
+	if err := json.NewDecoder(r).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to read the LLM answer: %w", err)
+	}
+	if len(answer.Choices) == 0 {
+		return nil, fmt.Errorf("the LLM answer holds no choices")
+	}
+
+	choice := answer.Choices[0]
+	completion := &entity.AICompletion{Content: choice.Message.Content, Truncated: choice.FinishReason == "length"}
+	if answer.Usage != nil {
+		completion.PromptTokens, completion.CompletionTokens = answer.Usage.PromptTokens, answer.Usage.CompletionTokens
+	}
+	onDelta(completion.Content)
+	return completion, nil
+}
+
+// responseError reads the {"error": {"message": ...}} the API answers a refused request with
+func responseError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	message := strings.TrimSpace(string(raw))
+	if json.Unmarshal(raw, &body) == nil && body.Error.Message != "" {
+		message = body.Error.Message
+	}
+	return fmt.Errorf("LLM request failed with %s: %s", resp.Status, message)
+}
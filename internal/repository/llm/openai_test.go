@@ -0,0 +1,66 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIAnalyzerStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "local-model", body["model"])
+		assert.Equal(t, float64(200), body["max_tokens"])
+		assert.Equal(t, true, body["stream"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"choices":[{"delta":{"role":"assistant","content":"### [high] "}}]}`,
+			`{"choices":[{"delta":{"content":"Filter on tenant_id"}}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"length"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":120,"completion_tokens":200}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+	}))
+	defer server.Close()
+
+	analyzer := llm.NewOpenAIAnalyzer(server.URL+"/v1/", "secret", "local-model", 200, time.Minute)
+
+	var deltas []string
+	completion, err := analyzer.Analyze(context.Background(), "system", "prompt", func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"### [high] ", "Filter on tenant_id"}, deltas)
+	assert.Equal(t, "### [high] Filter on tenant_id", completion.Content)
+	assert.True(t, completion.Truncated)
+	assert.Equal(t, 120, completion.PromptTokens)
+	assert.Equal(t, 200, completion.CompletionTokens)
+}
+
+func TestOpenAIAnalyzerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"model 'nope' not found"}}`)
+	}))
+	defer server.Close()
+
+	_, err := llm.NewOpenAIAnalyzer(server.URL, "", "nope", 0, time.Minute).Analyze(context.Background(), "system", "prompt", nil)
+	assert.EqualError(t, err, "LLM request failed with 400 Bad Request: model 'nope' not found")
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+
+	errwrap "github.com/pkg/errors"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/helper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AIAnalysisRepository interface {
+	FindByCacheKey(ctx context.Context, cacheKey string) (*entity.AIAnalysisCache, error)
+	Save(ctx context.Context, analysis *entity.AIAnalysisCache) error
+}
+
+type aiAnalysisRepository struct {
+	db *gorm.DB
+}
+
+func NewAIAnalysisRepository(db *gorm.DB) AIAnalysisRepository {
+	return &aiAnalysisRepository{db: db}
+}
+
+func (r *aiAnalysisRepository) FindByCacheKey(ctx context.Context, cacheKey string) (*entity.AIAnalysisCache, error) {
+	funcName := "AIAnalysisRepository.FindByCacheKey"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var analysis entity.AIAnalysisCache
+	err := r.db.WithContext(ctx).
+		Where("cache_key = ?", cacheKey).
+		Take(&analysis).Error
+
+	if err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &analysis, nil
+}
+
+// Save stores the answer, replacing the one cached under the same key
+func (r *aiAnalysisRepository) Save(ctx context.Context, analysis *entity.AIAnalysisCache) error {
+	funcName := "AIAnalysisRepository.Save"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cache_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"model", "normalized_query", "content", "prompt_tokens", "completion_tokens", "truncated", "created_at"}),
+		}).
+		Create(analysis).Error
+
+	if err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
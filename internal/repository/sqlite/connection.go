@@ -71,7 +71,25 @@ func (r *Connection) Delete(ctx context.Context, id int64) error {
 		return errwrap.Wrap(err, funcName)
 	}
 
-	return r.db.WithContext(ctx).Delete(&entity.CHConnection{}, id).Error
+	// Everything recorded for the connection goes with it
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dependents := []interface{}{
+			&entity.QueryHistory{},
+			&entity.FavoriteComparison{},
+			&entity.SlowQueryReport{},
+			&entity.ConnectionHealth{},
+		}
+		for _, model := range dependents {
+			if err := tx.Where("connection_id = ?", id).Delete(model).Error; err != nil {
+				return errwrap.Wrap(err, funcName)
+			}
+		}
+
+		if err := tx.Delete(&entity.CHConnection{}, id).Error; err != nil {
+			return errwrap.Wrap(err, funcName)
+		}
+		return nil
+	})
 }
 
 func (r *Connection) Update(ctx context.Context, conn *entity.CHConnection) error {
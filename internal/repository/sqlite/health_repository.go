@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+
+	errwrap "github.com/pkg/errors"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/helper"
+	"gorm.io/gorm"
+)
+
+type HealthRepository interface {
+	Create(ctx context.Context, health *entity.ConnectionHealth) error
+	FindLatestByConnectionID(ctx context.Context, connectionID int64) (*entity.ConnectionHealth, error)
+	FindLatestForAll(ctx context.Context) ([]*entity.ConnectionHealth, error)
+	FindByConnectionID(ctx context.Context, connectionID int64, limit int) ([]*entity.ConnectionHealth, error)
+	Prune(ctx context.Context, connectionID int64, maxLimit int) error
+}
+
+type healthRepository struct {
+	db *gorm.DB
+}
+
+func NewHealthRepository(db *gorm.DB) HealthRepository {
+	return &healthRepository{db: db}
+}
+
+func (r *healthRepository) Create(ctx context.Context, health *entity.ConnectionHealth) error {
+	funcName := "HealthRepository.Create"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	return r.db.WithContext(ctx).Create(health).Error
+}
+
+func (r *healthRepository) FindLatestByConnectionID(ctx context.Context, connectionID int64) (*entity.ConnectionHealth, error) {
+	funcName := "HealthRepository.FindLatestByConnectionID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var health entity.ConnectionHealth
+	err := r.db.WithContext(ctx).
+		Where("connection_id = ?", connectionID).
+		Order("checked_at desc").
+		Take(&health).Error
+
+	if err != nil {
+		if errwrap.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return &health, nil
+}
+
+func (r *healthRepository) FindLatestForAll(ctx context.Context) ([]*entity.ConnectionHealth, error) {
+	funcName := "HealthRepository.FindLatestForAll"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	// Latest row per connection: highest id wins since rows are append-only
+	var healths []*entity.ConnectionHealth
+	err := r.db.WithContext(ctx).
+		Where("id IN (?)",
+			r.db.Model(&entity.ConnectionHealth{}).
+				Select("MAX(id)").
+				Group("connection_id"),
+		).
+		Find(&healths).Error
+
+	if err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return healths, nil
+}
+
+func (r *healthRepository) FindByConnectionID(ctx context.Context, connectionID int64, limit int) ([]*entity.ConnectionHealth, error) {
+	funcName := "HealthRepository.FindByConnectionID"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	var healths []*entity.ConnectionHealth
+	err := r.db.WithContext(ctx).
+		Where("connection_id = ?", connectionID).
+		Order("checked_at desc").
+		Limit(limit).
+		Find(&healths).Error
+
+	if err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	return healths, nil
+}
+
+func (r *healthRepository) Prune(ctx context.Context, connectionID int64, maxLimit int) error {
+	funcName := "HealthRepository.Prune"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	// Same approach as QueryHistory.Prune, keep the newest maxLimit rows
+	return r.db.WithContext(ctx).
+		Where("connection_id = ? AND id NOT IN (?)", connectionID,
+			r.db.Model(&entity.ConnectionHealth{}).
+				Select("id").
+				Where("connection_id = ?", connectionID).
+				Order("checked_at desc").
+				Limit(maxLimit),
+		).
+		Delete(&entity.ConnectionHealth{}).Error
+}
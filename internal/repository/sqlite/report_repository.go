@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"time"
 
 	errwrap "github.com/pkg/errors"
 	"github.com/rahmatrdn/go-ch-manager/entity"
@@ -10,8 +11,9 @@ import (
 )
 
 type ReportRepository interface {
-	GetSlowQueryReports(ctx context.Context, connectionID int64) ([]*entity.SlowQueryReport, error)
-	SaveSlowQueryReports(ctx context.Context, connectionID int64, reports []*entity.SlowQueryReport) error
+	GetSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string) ([]*entity.SlowQueryReport, error)
+	SaveSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string, reports []*entity.SlowQueryReport) error
+	PruneSlowQueryReports(ctx context.Context, connectionID int64, before time.Time) error
 }
 
 type reportRepo struct {
@@ -22,15 +24,15 @@ func NewReportRepository(db *gorm.DB) ReportRepository {
 	return &reportRepo{db: db}
 }
 
-func (r *reportRepo) GetSlowQueryReports(ctx context.Context, connectionID int64) ([]*entity.SlowQueryReport, error) {
+func (r *reportRepo) GetSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string) ([]*entity.SlowQueryReport, error) {
 	funcName := "ReportRepository.GetSlowQueryReports"
 	if err := helper.CheckDeadline(ctx); err != nil {
 		return nil, errwrap.Wrap(err, funcName)
 	}
 
 	var reports []*entity.SlowQueryReport
-	// Get latest ones
-	err := r.db.WithContext(ctx).Where("connection_id = ?", connectionID).Order("max_duration_ms DESC").Find(&reports).Error
+	// Get latest ones, in the order the snapshot ranked them
+	err := r.db.WithContext(ctx).Where("connection_id = ? AND snapshot_key = ?", connectionID, snapshotKey).Order("rank").Find(&reports).Error
 	if err != nil {
 		return nil, errwrap.Wrap(err, funcName)
 	}
@@ -38,15 +40,15 @@ func (r *reportRepo) GetSlowQueryReports(ctx context.Context, connectionID int64
 	return reports, nil
 }
 
-func (r *reportRepo) SaveSlowQueryReports(ctx context.Context, connectionID int64, reports []*entity.SlowQueryReport) error {
+func (r *reportRepo) SaveSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string, reports []*entity.SlowQueryReport) error {
 	funcName := "ReportRepository.SaveSlowQueryReports"
 	if err := helper.CheckDeadline(ctx); err != nil {
 		return errwrap.Wrap(err, funcName)
 	}
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Delete existing entries for this connection
-		if err := tx.Where("connection_id = ?", connectionID).Delete(&entity.SlowQueryReport{}).Error; err != nil {
+		// Delete existing entries for this connection and filter
+		if err := tx.Where("connection_id = ? AND snapshot_key = ?", connectionID, snapshotKey).Delete(&entity.SlowQueryReport{}).Error; err != nil {
 			return errwrap.Wrap(err, funcName)
 		}
 
@@ -59,3 +61,19 @@ func (r *reportRepo) SaveSlowQueryReports(ctx context.Context, connectionID int6
 		return nil
 	})
 }
+
+// PruneSlowQueryReports drops the snapshots of a connection last refreshed before the given time
+func (r *reportRepo) PruneSlowQueryReports(ctx context.Context, connectionID int64, before time.Time) error {
+	funcName := "ReportRepository.PruneSlowQueryReports"
+	if err := helper.CheckDeadline(ctx); err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("connection_id = ? AND last_refresh < ?", connectionID, before).
+		Delete(&entity.SlowQueryReport{}).Error
+	if err != nil {
+		return errwrap.Wrap(err, funcName)
+	}
+	return nil
+}
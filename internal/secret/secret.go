@@ -0,0 +1,158 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	errwrap "github.com/pkg/errors"
+)
+
+// encryptedPrefix marks values produced by Encrypt
+const encryptedPrefix = "enc:v1:"
+
+// Cipher encrypts secrets (e.g. ClickHouse passwords) before they are persisted in SQLite
+type Cipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(value string) (string, error)
+	// IsEncrypted tells a stored value written by Encrypt from a plaintext one saved before encryption
+	IsEncrypted(value string) (bool, error)
+}
+
+type aesCipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher builds an AES-256-GCM cipher from the given master key
+func NewCipher(key []byte) (Cipher, error) {
+	funcName := "secret.NewCipher"
+
+	block, err := aes.NewCipher(deriveKey(key))
+	if err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	return &aesCipher{aead: aead}, nil
+}
+
+func (c *aesCipher) Encrypt(plaintext string) (string, error) {
+	funcName := "secret.Encrypt"
+
+	// Empty means "no password", nothing to protect. Anything else is encrypted, even when it looks encrypted already.
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errwrap.Wrap(err, funcName)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *aesCipher) Decrypt(value string) (string, error) {
+	funcName := "secret.Decrypt"
+
+	// Values without the prefix were stored before encryption was introduced
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	sealed, ok := c.sealed(value)
+	if !ok {
+		return "", errwrap.New("secret: malformed ciphertext")
+	}
+
+	nonceSize := c.aead.NonceSize()
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", errwrap.Wrap(err, funcName)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted only runs when migrating rows saved before encryption, so a legacy password that merely starts with the
+// prefix is still told apart: it does not decode to a sealed value. A sealed value this key cannot open is an error,
+// it was most likely encrypted with another master key and must not be encrypted twice.
+func (c *aesCipher) IsEncrypted(value string) (bool, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return false, nil
+	}
+	if _, ok := c.sealed(value); !ok {
+		return false, nil
+	}
+	if _, err := c.Decrypt(value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sealed decodes the nonce and ciphertext of a value written by Encrypt
+func (c *aesCipher) sealed(value string) ([]byte, bool) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < c.aead.NonceSize()+c.aead.Overhead() {
+		return nil, false
+	}
+	return sealed, true
+}
+
+// LoadKey resolves the master key from the env value first, then from keyFile.
+// When neither exists a new random key is generated and written to keyFile.
+func LoadKey(masterKey string, keyFile string) ([]byte, error) {
+	funcName := "secret.LoadKey"
+
+	if masterKey != "" {
+		return []byte(masterKey), nil
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err == nil {
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return nil, errwrap.Errorf("secret: key file %s is empty", keyFile)
+		}
+		return []byte(key), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0600); err != nil {
+		return nil, errwrap.Wrap(err, funcName)
+	}
+
+	return []byte(key), nil
+}
+
+// deriveKey accepts a base64 encoded 32 byte key as-is, any other passphrase is hashed into one
+func deriveKey(key []byte) []byte {
+	if decoded, err := base64.StdEncoding.DecodeString(string(key)); err == nil && len(decoded) == 32 {
+		return decoded
+	}
+
+	sum := sha256.Sum256(key)
+	return sum[:]
+}
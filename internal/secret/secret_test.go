@@ -0,0 +1,108 @@
+package secret_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	c, err := secret.NewCipher([]byte("my-master-key"))
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name      string
+		plaintext string
+	}{
+		{name: "Empty", plaintext: ""},
+		{name: "Simple", plaintext: "s3cr3t"},
+		{name: "Unicode", plaintext: "pässwörd-🔑"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := c.Encrypt(tt.plaintext)
+			require.NoError(t, err)
+
+			if tt.plaintext != "" {
+				assert.NotEqual(t, tt.plaintext, encrypted)
+				encryptedNow, err := c.IsEncrypted(encrypted)
+				require.NoError(t, err)
+				assert.True(t, encryptedNow)
+			}
+
+			decrypted, err := c.Decrypt(encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, decrypted)
+		})
+	}
+}
+
+func TestCipher_DecryptLegacyPlaintext(t *testing.T) {
+	c, err := secret.NewCipher([]byte("my-master-key"))
+	require.NoError(t, err)
+
+	decrypted, err := c.Decrypt("plain-password")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain-password", decrypted)
+}
+
+func TestCipher_EncryptLookalike(t *testing.T) {
+	c, err := secret.NewCipher([]byte("my-master-key"))
+	require.NoError(t, err)
+
+	// A password that only looks encrypted is still stored encrypted, and told apart from real ciphertext
+	encrypted, err := c.Encrypt("enc:v1:hunter2")
+	require.NoError(t, err)
+	assert.NotEqual(t, "enc:v1:hunter2", encrypted)
+
+	decrypted, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "enc:v1:hunter2", decrypted)
+
+	legacy, err := c.IsEncrypted("enc:v1:hunter2")
+	require.NoError(t, err)
+	assert.False(t, legacy)
+}
+
+func TestCipher_DecryptWrongKey(t *testing.T) {
+	c1, _ := secret.NewCipher([]byte("key-one"))
+	c2, _ := secret.NewCipher([]byte("key-two"))
+
+	encrypted, err := c1.Encrypt("s3cr3t")
+	require.NoError(t, err)
+
+	_, err = c2.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	// Encrypted with another key is not mistaken for a legacy plaintext password
+	_, err = c2.IsEncrypted(encrypted)
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "nested", "master.key")
+
+	key, err := secret.LoadKey("from-env", keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("from-env"), key)
+	assert.NoFileExists(t, keyFile)
+
+	generated, err := secret.LoadKey("", keyFile)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, generated)
+	assert.FileExists(t, keyFile)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reloaded, err := secret.LoadKey("", keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, generated, reloaded)
+}
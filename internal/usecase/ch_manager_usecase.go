@@ -3,13 +3,16 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/rahmatrdn/go-ch-manager/internal/lint"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/sqlite"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
 )
 
 type ConnectionUsecase struct {
@@ -17,20 +20,40 @@ type ConnectionUsecase struct {
 	historyRepo sqlite.QueryHistoryRepository
 	favRepo     sqlite.FavoriteRepository
 	chClient    clickhouse.ClickHouseClient
+	cipher      secret.Cipher
+	queryOpts   entity.QueryOptions
+	exportMax   uint64
+	cursors     *cursorRegistry
+	running     *runningQueries
 }
 
-func NewConnectionUsecase(repo sqlite.ConnectionRepository, historyRepo sqlite.QueryHistoryRepository, favRepo sqlite.FavoriteRepository, chClient clickhouse.ClickHouseClient) *ConnectionUsecase {
+// NewConnectionUsecase wires the connection usecase, queryOpts holds the result limits applied to console queries
+// and exportMax the size in bytes at which a result download stops (0 for no limit)
+func NewConnectionUsecase(repo sqlite.ConnectionRepository, historyRepo sqlite.QueryHistoryRepository, favRepo sqlite.FavoriteRepository, chClient clickhouse.ClickHouseClient, cipher secret.Cipher, queryOpts entity.QueryOptions, exportMax uint64) *ConnectionUsecase {
 	return &ConnectionUsecase{
 		repo:        repo,
 		historyRepo: historyRepo,
 		favRepo:     favRepo,
 		chClient:    chClient,
+		cipher:      cipher,
+		queryOpts:   queryOpts,
+		exportMax:   exportMax,
+		cursors:     newCursorRegistry(),
+		running:     newRunningQueries(),
 	}
 }
 
 func (u *ConnectionUsecase) CreateConnection(ctx context.Context, conn *entity.CHConnection) error {
 	conn.CreatedAt = time.Now()
 	conn.UpdatedAt = time.Now()
+
+	// Encrypt before anything else touches it, the ClickHouse client decrypts on dial
+	password, err := u.cipher.Encrypt(conn.Password)
+	if err != nil {
+		return err
+	}
+	conn.Password = password
+
 	// Optionally test connection before saving?
 	// Try to ping the connection
 	if err := u.chClient.Ping(ctx, conn); err != nil {
@@ -60,6 +83,23 @@ func (u *ConnectionUsecase) UpdateConnection(ctx context.Context, id int64, conn
 	conn.UpdatedAt = time.Now()
 	conn.CreatedAt = existing.CreatedAt // Preserve created_at
 
+	// Password is never sent back to the browser, so an empty one means "keep current" unless it is cleared
+	switch {
+	case conn.ClearPassword:
+		conn.Password = ""
+	case conn.Password == "":
+		conn.Password = existing.Password
+	default:
+		password, err := u.cipher.Encrypt(conn.Password)
+		if err != nil {
+			return err
+		}
+		conn.Password = password
+	}
+
+	// Drop pools dialed with the previous settings before validating the new ones
+	u.chClient.Invalidate(id)
+
 	// Optional: validate connection
 	if err := u.chClient.Ping(ctx, conn); err != nil {
 		return err
@@ -74,8 +114,168 @@ func (u *ConnectionUsecase) UpdateConnection(ctx context.Context, id int64, conn
 	return u.repo.Update(ctx, conn)
 }
 
+// DeleteConnection removes the connection together with its history, favorites, reports and health checks
+func (u *ConnectionUsecase) DeleteConnection(ctx context.Context, id int64) error {
+	existing, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return apperr.ErrRecordNotFound()
+	}
+
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	u.chClient.Invalidate(id)
+	return nil
+}
+
+// DuplicateConnection saves a copy of the connection under a free "(copy)" name, the password is carried over
+func (u *ConnectionUsecase) DuplicateConnection(ctx context.Context, id int64) (*entity.CHConnection, error) {
+	existing, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		taken[conn.Name] = true
+	}
+
+	name := existing.Name + " (copy)"
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s (copy %d)", existing.Name, i)
+	}
+
+	duplicate := *existing
+	duplicate.ID = 0
+	duplicate.Name = name
+	duplicate.CreatedAt = time.Now()
+	duplicate.UpdatedAt = time.Now()
+
+	if err := u.repo.Create(ctx, &duplicate); err != nil {
+		return nil, err
+	}
+	return duplicate.Redact(), nil
+}
+
+// TestConnection dials an unsaved connection and reports how far it got.
+// When the payload carries the ID of a saved connection and no password, the stored password is used unless it is
+// being cleared.
+func (u *ConnectionUsecase) TestConnection(ctx context.Context, conn *entity.CHConnection) (*entity.ConnectionTestResult, error) {
+	candidate := *conn
+
+	if candidate.Password == "" && candidate.ID != 0 && !candidate.ClearPassword {
+		existing, err := u.repo.FindByID(ctx, candidate.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			candidate.Password = existing.Password
+		}
+	} else {
+		password, err := u.cipher.Encrypt(candidate.Password)
+		if err != nil {
+			return nil, err
+		}
+		candidate.Password = password
+	}
+
+	result := &entity.ConnectionTestResult{
+		Stage:     entity.ConnTestStageConfig,
+		Endpoints: candidate.Endpoints(),
+	}
+
+	if candidate.Host == "" || candidate.Port <= 0 {
+		result.Error = "host and port are required"
+		return result, nil
+	}
+	if _, err := clickhouse.BuildTLSConfig(&candidate); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Dialed apart from the pools so an earlier pool never answers for these credentials
+	u.chClient.TestConnection(ctx, &candidate, result)
+	return result, nil
+}
+
+// GetPoolStats lists the open driver pools, named after their saved connection
+func (u *ConnectionUsecase) GetPoolStats(ctx context.Context) ([]entity.PoolStats, error) {
+	stats := u.chClient.PoolStats()
+
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(conns))
+	for _, conn := range conns {
+		names[conn.ID] = conn.Name
+	}
+	for i := range stats {
+		stats[i].ConnectionName = names[stats[i].ConnectionID]
+	}
+
+	return stats, nil
+}
+
+// GetAllConnections returns redacted connections, meant for listing in API responses and views
 func (u *ConnectionUsecase) GetAllConnections(ctx context.Context) ([]*entity.CHConnection, error) {
-	return u.repo.FindAll(ctx)
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]*entity.CHConnection, 0, len(conns))
+	for _, conn := range conns {
+		redacted = append(redacted, conn.Redact())
+	}
+	return redacted, nil
+}
+
+// EncryptStoredPasswords migrates connections saved before encryption was introduced
+func (u *ConnectionUsecase) EncryptStoredPasswords(ctx context.Context) (int, error) {
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, conn := range conns {
+		if conn.Password == "" {
+			continue
+		}
+		encrypted, err := u.cipher.IsEncrypted(conn.Password)
+		if err != nil {
+			return migrated, fmt.Errorf("password of %q cannot be decrypted with this master key: %w", conn.Name, err)
+		}
+		if encrypted {
+			continue
+		}
+
+		password, err := u.cipher.Encrypt(conn.Password)
+		if err != nil {
+			return migrated, err
+		}
+		conn.Password = password
+
+		if err := u.repo.Update(ctx, conn); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
 }
 
 func (u *ConnectionUsecase) GetConnectionStatus(ctx context.Context, id int64) (string, error) {
@@ -87,12 +287,10 @@ func (u *ConnectionUsecase) GetConnectionStatus(ctx context.Context, id int64) (
 		return "Not Found", nil
 	}
 
-	// err = u.chClient.Ping(ctx, conn)
-
-	// if err != nil {
-	// 	return "Offline", nil
-	// }
-	return "Online", nil
+	if err := u.chClient.Ping(ctx, conn); err != nil {
+		return entity.HealthStatusOffline, nil
+	}
+	return entity.HealthStatusOnline, nil
 }
 
 func (u *ConnectionUsecase) GetServerInfo(ctx context.Context, id int64) (string, error) {
@@ -166,58 +364,73 @@ func (u *ConnectionUsecase) GetQueryHistory(ctx context.Context, connectionID in
 	return u.historyRepo.FindByConnectionID(ctx, connectionID, 50)
 }
 
-func (u *ConnectionUsecase) CompareQueries(ctx context.Context, id int64, query1, query2 string) (*entity.CompareResult, error) {
+// ExecuteQuery runs a console query under req.QueryID (see ResolveQueryID), CancelQuery stops it while it runs.
+// DDL/DML on a PRODUCTION connection needs req.Confirm.
+func (u *ConnectionUsecase) ExecuteQuery(ctx context.Context, id int64, req entity.QueryRequest) (*entity.QueryResult, error) {
 	conn, err := u.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if conn == nil {
-		return nil, nil // Or error not found
+		return nil, nil // Or return not found error
 	}
 
-	stats1, err := u.chClient.ExecuteQueryWithStats(ctx, conn, query1)
+	query, err := singleStatement(req.Query)
 	if err != nil {
 		return nil, err
 	}
 
-	stats2, err := u.chClient.ExecuteQueryWithStats(ctx, conn, query2)
-	if err != nil {
+	if err := guardStatements(conn, req.Confirm, query); err != nil {
 		return nil, err
 	}
 
-	return &entity.CompareResult{
-		Query1Stats: stats1,
-		Query2Stats: stats2,
-	}, nil
-}
+	params, err := bindParameters(query, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
 
-func (u *ConnectionUsecase) ExecuteQuery(ctx context.Context, id int64, query string) (*entity.QueryResult, error) {
-	conn, err := u.repo.FindByID(ctx, id)
+	settings, err := u.querySettings(ctx, conn, req.Settings)
 	if err != nil {
 		return nil, err
 	}
-	if conn == nil {
-		return nil, nil // Or return not found error
+
+	queryCtx, done, err := u.trackQuery(ctx, id, req.QueryID)
+	if err != nil {
+		return nil, err
 	}
+	defer done()
+
+	opts := u.queryOpts
+	opts.QueryID = req.QueryID
+	opts.Parameters = params
+	opts.Settings = settings
 
-	result, err := u.chClient.ExecuteQueryWithResults(ctx, conn, query)
+	result, err := u.chClient.ExecuteQueryWithResults(queryCtx, conn, query, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	u.saveHistory(id, settings, query)
+
+	return result, nil
+}
+
+// saveHistory records the executed queries with the settings they ran with, so a history entry can be run again as it was
+func (u *ConnectionUsecase) saveHistory(id int64, settings map[string]string, queries ...string) {
 	// Save to history (Async or Sync? Sync for now to simple)
 	go func() {
 		// Create a new context for the background task to avoid cancellation if the request context is cancelled
 		bgCtx := context.Background()
-		history := &entity.QueryHistory{
-			ConnectionID: id,
-			Query:        query,
+		for _, query := range queries {
+			history := &entity.QueryHistory{
+				ConnectionID: id,
+				Query:        query,
+				Settings:     settings,
+			}
+			_ = u.historyRepo.Create(bgCtx, history)
 		}
-		_ = u.historyRepo.Create(bgCtx, history)
 		_ = u.historyRepo.Prune(bgCtx, id, 50)
 	}()
-
-	return result, nil
 }
 
 func (u *ConnectionUsecase) GetConfigurationData(ctx context.Context, id int64) (*entity.ConfigurationData, error) {
@@ -278,7 +491,26 @@ func (u *ConnectionUsecase) DeleteFavoriteComparison(ctx context.Context, id int
 	return u.favRepo.Delete(ctx, id)
 }
 
-func (u *ConnectionUsecase) AnalyzeQuery(ctx context.Context, id int64, query string) (*entity.QueryAnalysis, error) {
+// Limits of a sampled analyze run, unless the request sets its own
+const (
+	analyzeSampleRows    = 1_000_000
+	analyzeSampleSeconds = 10
+)
+
+// AnalyzeQuery explains req.Query and gets its stats the way req.Mode asks: running it read-only with its parameters
+// and settings, under read limits, or not at all. Then it gathers the schemas of the tables it reads.
+func (u *ConnectionUsecase) AnalyzeQuery(ctx context.Context, id int64, req entity.AnalyzeRequest) (*entity.QueryAnalysis, error) {
+	query := req.Query
+
+	mode := req.Mode
+	switch mode {
+	case "":
+		mode = entity.AnalyzeModeFull
+	case entity.AnalyzeModeFull, entity.AnalyzeModeDryRun, entity.AnalyzeModeSampled:
+	default:
+		return nil, fmt.Errorf("unknown analyze mode %q", mode)
+	}
+
 	conn, err := u.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -292,28 +524,70 @@ func (u *ConnectionUsecase) AnalyzeQuery(ctx context.Context, id int64, query st
 		conn.Database = "default"
 	}
 
-	// Run EXPLAIN on the query
-	explainPlan, err := u.chClient.ExplainQuery(ctx, conn, query)
+	bound, err := bindParameters(query, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := u.querySettings(ctx, conn, req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	// The query is executed for its stats, it must not change anything
+	opts := entity.QueryOptions{Parameters: bound, Settings: settings, ReadOnly: true}
+
+	// Run EXPLAIN on the query, the plan shows how the indexes pruned each table
+	var explainPlan string
+	plan, err := u.explain(ctx, conn, entity.ExplainKindPlan, query, opts)
 	if err != nil {
 		explainPlan = fmt.Sprintf("-- Failed to get EXPLAIN plan: %v", err)
+	} else {
+		explainPlan = plan.Text
 	}
 
-	// Execute the query to get stats
-	queryStats, err := u.chClient.ExecuteQueryWithStats(ctx, conn, query)
+	analysis := &entity.QueryAnalysis{Query: query, ExplainPlan: explainPlan, Mode: mode}
+	if mode != entity.AnalyzeModeFull {
+		analysis.Estimate = u.estimateRead(ctx, conn, query, opts, plan)
+	}
+
+	// Execute the query to get stats, a sampled run stops at its limits
+	switch mode {
+	case entity.AnalyzeModeFull:
+		analysis.QueryStats, err = u.chClient.ExecuteQueryWithStats(ctx, conn, query, opts)
+	case entity.AnalyzeModeSampled:
+		analysis.SampleRows, analysis.SampleSeconds = req.SampleRows, req.SampleSeconds
+		if analysis.SampleRows == 0 {
+			analysis.SampleRows = analyzeSampleRows
+		}
+		if analysis.SampleSeconds == 0 {
+			analysis.SampleSeconds = analyzeSampleSeconds
+		}
+		sampleOpts := opts
+		sampleOpts.MaxRowsToRead, sampleOpts.MaxExecutionTime = analysis.SampleRows, analysis.SampleSeconds
+
+		analysis.QueryStats, err = u.chClient.ExecuteQueryWithStats(ctx, conn, query, sampleOpts)
+		if err == nil {
+			// Without its query_log entry nothing tells whether the run was cut off, so it is not reported as complete
+			stats := analysis.QueryStats
+			stats.Partial = stats.Unavailable || stats.RowsRead >= analysis.SampleRows || stats.ExecutionTimeMs >= int64(analysis.SampleSeconds)*1000
+		}
+	}
 	if err != nil {
-		queryStats = nil
+		analysis.QueryStats = nil
 	}
 
-	// Extract table references from query (with database)
-	tableRefs := u.extractTableReferences(query)
+	// Resolve what the query refers to, CTEs, aliases and table functions left out
+	refs := chsql.ExtractReferences(query)
 
 	// Fetch CREATE TABLE statements for each table
 	tableSchemas := make([]entity.TableSchemaInfo, 0)
 	warnings := make([]string, 0)
 
-	for _, ref := range tableRefs {
+	for _, ref := range refs.Tables {
+		if strings.EqualFold(ref.Database, "system") {
+			continue
+		}
 		database := ref.Database
-		tableName := ref.TableName
+		tableName := ref.Table
 		hasWarning := false
 
 		// If database not specified in query, use connection's default database
@@ -341,116 +615,33 @@ func (u *ConnectionUsecase) AnalyzeQuery(ctx context.Context, id int64, query st
 		})
 	}
 
-	// Generate analysis text
-	analysisText := u.generateAnalysisText(query, explainPlan, queryStats, tableSchemas, warnings)
-
-	return &entity.QueryAnalysis{
-		Query:        query,
-		Tables:       tableSchemas,
-		ExplainPlan:  explainPlan,
-		QueryStats:   queryStats,
-		AnalysisText: analysisText,
-		Warnings:     warnings,
-	}, nil
-}
-
-func (u *ConnectionUsecase) extractTableNames(query string) []string {
-	// Normalize query - remove comments and extra whitespace
-	normalizedQuery := strings.ToUpper(query)
-
-	// Common SQL patterns to extract table names
-	patterns := []string{
-		`FROM\s+([^\s,(]+)`,   // FROM table
-		`JOIN\s+([^\s,(]+)`,   // JOIN table
-		`INTO\s+([^\s,(]+)`,   // INSERT INTO table
-		`UPDATE\s+([^\s,(]+)`, // UPDATE table
-		`TABLE\s+([^\s,(]+)`,  // DROP TABLE, CREATE TABLE, etc.
-	}
-
-	tableNames := make([]string, 0)
-	seen := make(map[string]bool)
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindAllStringSubmatch(normalizedQuery, -1)
-		for _, match := range matches {
-			if len(match) > 1 {
-				tableName := strings.Trim(match[1], "`\"")
-				// Skip subqueries and system tables
-				if !strings.Contains(tableName, "(") &&
-					!strings.HasPrefix(tableName, "SYSTEM.") &&
-					!seen[tableName] &&
-					tableName != "" {
-					seen[tableName] = true
-					tableNames = append(tableNames, tableName)
-				}
-			}
+	columns := make([]string, len(refs.Columns))
+	for i, col := range refs.Columns {
+		columns[i] = col.Name
+		if col.Table != "" {
+			columns[i] = col.Table + "." + col.Name
 		}
 	}
 
-	return tableNames
-}
-
-type tableReference struct {
-	Database  string
-	TableName string
-}
-
-func (u *ConnectionUsecase) extractTableReferences(query string) []tableReference {
-	// Common SQL patterns to extract table references (database.table or just table)
-	patterns := []string{
-		`FROM\s+([^\s,(]+)`,   // FROM table
-		`JOIN\s+([^\s,(]+)`,   // JOIN table
-		`INTO\s+([^\s,(]+)`,   // INSERT INTO table
-		`UPDATE\s+([^\s,(]+)`, // UPDATE table
-		`TABLE\s+([^\s,(]+)`,  // DROP TABLE, CREATE TABLE, etc.
-	}
-
-	references := make([]tableReference, 0)
-	seen := make(map[string]bool)
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindAllStringSubmatch(query, -1) // Use original query for case sensitivity
-		for _, match := range matches {
-			if len(match) > 1 {
-				fullRef := strings.Trim(match[1], "`\"")
-
-				// Skip subqueries and system tables
-				if strings.Contains(fullRef, "(") || strings.HasPrefix(strings.ToUpper(fullRef), "SYSTEM.") {
-					continue
-				}
-
-				// Create unique key for deduplication
-				uniqueKey := strings.ToUpper(fullRef)
-				if seen[uniqueKey] {
-					continue
-				}
-				seen[uniqueKey] = true
-
-				// Split by dot to get database and table
-				parts := strings.Split(fullRef, ".")
-				var database, tableName string
-
-				if len(parts) >= 2 {
-					database = parts[0]
-					tableName = strings.Join(parts[1:], ".") // Handle case like db.schema.table
-				} else {
-					tableName = fullRef
-				}
-
-				references = append(references, tableReference{
-					Database:  database,
-					TableName: tableName,
-				})
-			}
-		}
+	lintTables := make([]lint.Table, len(tableSchemas))
+	for i, schema := range tableSchemas {
+		lintTables[i] = lint.Table{Database: schema.Database, Name: schema.TableName, CreateSQL: schema.CreateSQL}
 	}
 
-	return references
+	analysis.Tables = tableSchemas
+	analysis.TableFunctions = refs.TableFunctions
+	analysis.Dictionaries = refs.Dictionaries
+	analysis.Columns = columns
+	analysis.Warnings = warnings
+	analysis.Findings = lint.Check(lint.Input{Query: query, ExplainPlan: explainPlan, Tables: lintTables})
+	analysis.AnalysisText = u.generateAnalysisText(analysis)
+	return analysis, nil
 }
 
-func (u *ConnectionUsecase) generateAnalysisText(query string, explainPlan string, queryStats *entity.QueryStats, tableSchemas []entity.TableSchemaInfo, warnings []string) string {
+func (u *ConnectionUsecase) generateAnalysisText(analysis *entity.QueryAnalysis) string {
+	query, explainPlan, queryStats := analysis.Query, analysis.ExplainPlan, analysis.QueryStats
+	tableSchemas, warnings := analysis.Tables, analysis.Warnings
+
 	var sb strings.Builder
 
 	sb.WriteString("## Query Analysis\n\n")
@@ -480,15 +671,50 @@ func (u *ConnectionUsecase) generateAnalysisText(query string, explainPlan strin
 		sb.WriteString(fmt.Sprintf("%s\n\n", explainPlan))
 	}
 
+	if estimate := analysis.Estimate; estimate != nil {
+		sb.WriteString("### Estimated Read\n")
+		if estimate.Source == entity.ExplainKindEstimate {
+			sb.WriteString(fmt.Sprintf("- **Rows**: %s\n", formatNumber(estimate.Rows)))
+		}
+		sb.WriteString(fmt.Sprintf("- **Parts**: %s\n", formatNumber(estimate.Parts)))
+		sb.WriteString(fmt.Sprintf("- **Marks**: %s\n", formatNumber(estimate.Marks)))
+		for _, t := range estimate.Tables {
+			sb.WriteString(fmt.Sprintf("- `%s.%s`: %s rows, %s parts, %s marks\n", t.Database, t.Table, formatNumber(t.Rows), formatNumber(t.Parts), formatNumber(t.Marks)))
+		}
+		sb.WriteString(fmt.Sprintf("_From EXPLAIN %s_\n\n", strings.ToUpper(estimate.Source)))
+	}
+
 	// Add Query Execution Stats
 	sb.WriteString("### Current Execution Stats\n")
-	if queryStats != nil {
+	if queryStats != nil && queryStats.Partial && queryStats.Unavailable {
+		sb.WriteString(fmt.Sprintf("_Sampled run limited to %s rows or %d s, whether it stopped early is unknown_\n",
+			formatNumber(analysis.SampleRows), analysis.SampleSeconds))
+	} else if queryStats != nil && queryStats.Partial {
+		sb.WriteString(fmt.Sprintf("_Sampled run stopped at %s rows or %d s, the stats below only cover what was read until then_\n",
+			formatNumber(analysis.SampleRows), analysis.SampleSeconds))
+	}
+	if analysis.Mode == entity.AnalyzeModeDryRun {
+		sb.WriteString("_Dry run, the query was not executed_\n")
+	} else if queryStats != nil && queryStats.Unavailable {
+		sb.WriteString(fmt.Sprintf("- **Duration**: %d ms (measured by the client)\n", queryStats.ExecutionTimeMs))
+		sb.WriteString("_The query_log entry was not found, other stats are not available_\n")
+	} else if queryStats != nil {
 		sb.WriteString(fmt.Sprintf("- **Duration**: %d ms\n", queryStats.ExecutionTimeMs))
 		sb.WriteString(fmt.Sprintf("- **Rows Read**: %s\n", formatNumber(uint64(queryStats.RowsRead))))
 		sb.WriteString(fmt.Sprintf("- **Bytes Read**: %s\n", formatBytes(queryStats.BytesRead)))
 		sb.WriteString(fmt.Sprintf("- **Memory Peak**: %s\n", formatBytes(queryStats.MemoryPeak)))
 		sb.WriteString(fmt.Sprintf("- **Parts Read**: %s\n", formatNumber(queryStats.PartsRead)))
 		sb.WriteString(fmt.Sprintf("- **Marks Read**: %s\n", formatNumber(queryStats.MarksRead)))
+		sb.WriteString(fmt.Sprintf("- **Result Rows**: %s\n", formatNumber(queryStats.ResultRows)))
+		sb.WriteString(fmt.Sprintf("- **CPU Time**: %d ms user, %d ms system over %d threads\n", queryStats.UserTimeUs/1000, queryStats.SystemTimeUs/1000, queryStats.Threads))
+		sb.WriteString(fmt.Sprintf("- **Network**: %s received, %s sent\n", formatBytes(queryStats.NetworkReceiveBytes), formatBytes(queryStats.NetworkSendBytes)))
+		sb.WriteString(fmt.Sprintf("- **Mark Cache**: %s hits, %s misses\n", formatNumber(queryStats.MarkCacheHits), formatNumber(queryStats.MarkCacheMisses)))
+		if queryStats.QueryCacheHits > 0 {
+			sb.WriteString("- **Query Cache**: served from the query cache\n")
+		}
+		if queryStats.SecondaryQueries > 0 {
+			sb.WriteString(fmt.Sprintf("- **Distributed**: %d secondary queries on other servers, counted in the stats above\n", queryStats.SecondaryQueries))
+		}
 	} else {
 		sb.WriteString("_Stats not available_\n")
 	}
@@ -512,6 +738,24 @@ func (u *ConnectionUsecase) generateAnalysisText(query string, explainPlan strin
 		}
 	}
 
+	if len(analysis.Dictionaries) > 0 {
+		sb.WriteString(fmt.Sprintf("### Dictionaries\n\n%s\n\n", strings.Join(analysis.Dictionaries, ", ")))
+	}
+	if len(analysis.TableFunctions) > 0 {
+		sb.WriteString(fmt.Sprintf("### Table Functions\n\n%s\n\n", strings.Join(analysis.TableFunctions, ", ")))
+	}
+	if len(analysis.Columns) > 0 {
+		sb.WriteString(fmt.Sprintf("### Referenced Columns\n\n%s\n\n", strings.Join(analysis.Columns, ", ")))
+	}
+
+	if len(analysis.Findings) > 0 {
+		sb.WriteString(fmt.Sprintf("### Lint Findings (%d)\n\n", len(analysis.Findings)))
+		for _, f := range analysis.Findings {
+			sb.WriteString(fmt.Sprintf("- **[%s] %s**: %s\n  - Fix: %s\n", f.Severity, f.RuleID, f.Message, f.Suggestion))
+		}
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("### Analysis Request\n\n")
 	sb.WriteString("Please provide:\n")
 	sb.WriteString("1. Performance optimization suggestions\n")
@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalConnectionProfiles encodes an export as "json" or "yaml"
+func MarshalConnectionProfiles(file *entity.ConnectionProfileFile, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return json.MarshalIndent(file, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(file)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q, use json or yaml", format)
+	}
+}
+
+// UnmarshalConnectionProfiles decodes an export written as JSON or YAML and checks its version
+func UnmarshalConnectionProfiles(data []byte) (*entity.ConnectionProfileFile, error) {
+	var file entity.ConnectionProfileFile
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("connection profile file is empty")
+	}
+
+	var err error
+	if trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &file)
+	} else {
+		err = yaml.Unmarshal(trimmed, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection profile file: %w", err)
+	}
+
+	if file.Version == 0 {
+		return nil, fmt.Errorf("connection profile file has no version")
+	}
+	if file.Version > entity.ConnectionProfileVersion {
+		return nil, fmt.Errorf("connection profile version %d is newer than supported version %d", file.Version, entity.ConnectionProfileVersion)
+	}
+
+	return &file, nil
+}
+
+// ExportConnections builds a portable profile of every saved connection.
+// Passwords are decrypted into the file only when withSecrets is set, the master key never leaves the server.
+func (u *ConnectionUsecase) ExportConnections(ctx context.Context, withSecrets bool) (*entity.ConnectionProfileFile, error) {
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &entity.ConnectionProfileFile{
+		Version:     entity.ConnectionProfileVersion,
+		ExportedAt:  time.Now(),
+		WithSecrets: withSecrets,
+		Connections: make([]entity.ConnectionProfile, 0, len(conns)),
+	}
+
+	for _, conn := range conns {
+		profile := toConnectionProfile(conn)
+		if withSecrets {
+			password, err := u.cipher.Decrypt(conn.Password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt password of %q: %w", conn.Name, err)
+			}
+			profile.Password = password
+		}
+		file.Connections = append(file.Connections, profile)
+	}
+
+	return file, nil
+}
+
+// ImportConnections upserts the profiles by name. Every new or changed entry is pinged before it is saved,
+// saved connections that differ are only replaced when overwrite is set.
+func (u *ConnectionUsecase) ImportConnections(ctx context.Context, file *entity.ConnectionProfileFile, overwrite bool) (*entity.ConnectionImportResult, error) {
+	conns, err := u.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]*entity.CHConnection, len(conns))
+	for _, conn := range conns {
+		byName[conn.Name] = append(byName[conn.Name], conn)
+	}
+
+	result := &entity.ConnectionImportResult{}
+	seen := make(map[string]bool, len(file.Connections))
+
+	for _, profile := range file.Connections {
+		entry := u.importConnection(ctx, normalizeProfile(profile), byName, seen, overwrite)
+
+		switch entry.Status {
+		case entity.ImportStatusCreated:
+			result.Created++
+		case entity.ImportStatusUpdated:
+			result.Updated++
+		case entity.ImportStatusUnchanged:
+			result.Unchanged++
+		case entity.ImportStatusConflict:
+			result.Conflicts++
+		case entity.ImportStatusFailed:
+			result.Failed++
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+func (u *ConnectionUsecase) importConnection(ctx context.Context, profile entity.ConnectionProfile, byName map[string][]*entity.CHConnection, seen map[string]bool, overwrite bool) entity.ConnectionImportEntry {
+	entry := entity.ConnectionImportEntry{Name: profile.Name, Status: entity.ImportStatusFailed}
+
+	if profile.Name == "" || profile.Host == "" || profile.Port <= 0 {
+		entry.Message = "name, host and port are required"
+		return entry
+	}
+
+	if seen[profile.Name] {
+		entry.Status = entity.ImportStatusConflict
+		entry.Message = "name appears more than once in the file, only the first entry is imported"
+		return entry
+	}
+	seen[profile.Name] = true
+
+	matches := byName[profile.Name]
+	if len(matches) > 1 {
+		entry.Status = entity.ImportStatusConflict
+		entry.Message = fmt.Sprintf("%d saved connections share this name", len(matches))
+		return entry
+	}
+
+	var existing *entity.CHConnection
+	if len(matches) == 1 {
+		existing = matches[0]
+	}
+
+	if existing != nil {
+		same, err := u.sameProfile(existing, profile)
+		if err != nil {
+			entry.Message = err.Error()
+			return entry
+		}
+		if same {
+			entry.Status = entity.ImportStatusUnchanged
+			return entry
+		}
+		if !overwrite {
+			entry.Status = entity.ImportStatusConflict
+			entry.Message = "differs from the saved connection, import with overwrite to replace it"
+			return entry
+		}
+	}
+
+	conn := fromConnectionProfile(profile)
+	if profile.Password != "" {
+		password, err := u.cipher.Encrypt(profile.Password)
+		if err != nil {
+			entry.Message = err.Error()
+			return entry
+		}
+		conn.Password = password
+	} else if existing != nil {
+		conn.Password = existing.Password
+	}
+
+	// Validate on a connection dialed for the check alone and closed after it, before anything is written
+	check := &entity.ConnectionTestResult{}
+	u.chClient.TestConnection(ctx, conn, check)
+	if check.Stage == entity.ConnTestStagePing && !check.Success {
+		entry.Message = check.Error
+		return entry
+	}
+	conn.ServerInfo = check.ServerVersion
+
+	now := time.Now()
+	conn.UpdatedAt = now
+
+	if existing == nil {
+		conn.CreatedAt = now
+		if err := u.repo.Create(ctx, conn); err != nil {
+			entry.Message = err.Error()
+			return entry
+		}
+		byName[conn.Name] = []*entity.CHConnection{conn}
+		entry.Status = entity.ImportStatusCreated
+		return entry
+	}
+
+	conn.ID = existing.ID
+	conn.CreatedAt = existing.CreatedAt
+	if err := u.repo.Update(ctx, conn); err != nil {
+		entry.Message = err.Error()
+		return entry
+	}
+	u.chClient.Invalidate(existing.ID)
+
+	entry.Status = entity.ImportStatusUpdated
+	return entry
+}
+
+// sameProfile reports whether importing the profile would change nothing, a missing password keeps the saved one
+func (u *ConnectionUsecase) sameProfile(existing *entity.CHConnection, profile entity.ConnectionProfile) (bool, error) {
+	current := toConnectionProfile(existing)
+	incoming := profile
+	incoming.Password = ""
+
+	if current != incoming {
+		return false, nil
+	}
+	if profile.Password == "" {
+		return true, nil
+	}
+
+	password, err := u.cipher.Decrypt(existing.Password)
+	if err != nil {
+		return false, err
+	}
+	return password == profile.Password, nil
+}
+
+func toConnectionProfile(conn *entity.CHConnection) entity.ConnectionProfile {
+	return normalizeProfile(entity.ConnectionProfile{
+		Name:             conn.Name,
+		Host:             conn.Host,
+		Port:             conn.Port,
+		Hosts:            conn.Hosts,
+		ConnOpenStrategy: conn.ConnOpenStrategy,
+		Username:         conn.Username,
+		Database:         conn.Database,
+		Protocol:         conn.Protocol,
+		Label:            conn.Label,
+		UseSSL:           conn.UseSSL,
+		TLSCACert:        conn.TLSCACert,
+		TLSClientCert:    conn.TLSClientCert,
+		TLSClientKey:     conn.TLSClientKey,
+		TLSServerName:    conn.TLSServerName,
+		TLSSkipVerify:    conn.TLSSkipVerify,
+		MaxOpenConns:     conn.MaxOpenConns,
+		MaxIdleConns:     conn.MaxIdleConns,
+	})
+}
+
+func fromConnectionProfile(profile entity.ConnectionProfile) *entity.CHConnection {
+	return &entity.CHConnection{
+		Name:             profile.Name,
+		Host:             profile.Host,
+		Port:             profile.Port,
+		Hosts:            profile.Hosts,
+		ConnOpenStrategy: profile.ConnOpenStrategy,
+		Username:         profile.Username,
+		Database:         profile.Database,
+		Protocol:         profile.Protocol,
+		Label:            profile.Label,
+		UseSSL:           profile.UseSSL,
+		TLSCACert:        profile.TLSCACert,
+		TLSClientCert:    profile.TLSClientCert,
+		TLSClientKey:     profile.TLSClientKey,
+		TLSServerName:    profile.TLSServerName,
+		TLSSkipVerify:    profile.TLSSkipVerify,
+		MaxOpenConns:     profile.MaxOpenConns,
+		MaxIdleConns:     profile.MaxIdleConns,
+	}
+}
+
+// normalizeProfile fills the same defaults the database applies, so saved and imported profiles compare equal
+func normalizeProfile(profile entity.ConnectionProfile) entity.ConnectionProfile {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Protocol == "" {
+		profile.Protocol = "native"
+	}
+	if profile.ConnOpenStrategy == "" {
+		profile.ConnOpenStrategy = entity.ConnOpenInOrder
+	}
+	if profile.Label == "" {
+		profile.Label = "DEVELOPMENT"
+	}
+	return profile
+}
@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionProfilesRoundTrip(t *testing.T) {
+	file := &entity.ConnectionProfileFile{
+		Version:     entity.ConnectionProfileVersion,
+		ExportedAt:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		WithSecrets: true,
+		Connections: []entity.ConnectionProfile{
+			{
+				Name:             "prod",
+				Host:             "ch-1.example.com",
+				Port:             9440,
+				Hosts:            "ch-2.example.com",
+				ConnOpenStrategy: entity.ConnOpenRoundRobin,
+				Username:         "default",
+				Password:         "s3cret",
+				UseSSL:           true,
+				TLSCACert:        "/etc/ssl/ca.pem",
+			},
+		},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := usecase.MarshalConnectionProfiles(file, format)
+			require.NoError(t, err)
+
+			decoded, err := usecase.UnmarshalConnectionProfiles(data)
+			require.NoError(t, err)
+			assert.Equal(t, file.Connections, decoded.Connections)
+			assert.True(t, file.ExportedAt.Equal(decoded.ExportedAt))
+		})
+	}
+}
+
+func TestUnmarshalConnectionProfilesErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		data string
+	}{
+		{name: "Empty", data: "  "},
+		{name: "Missing Version", data: `{"connections": []}`},
+		{name: "Newer Version", data: "version: 99\nconnections: []\n"},
+		{name: "Malformed", data: "version: [1"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.UnmarshalConnectionProfiles([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := usecase.MarshalConnectionProfiles(&entity.ConnectionProfileFile{}, "xml")
+	assert.Error(t, err)
+}
+
+// importFixture saves "staging" and "prod" once and "shared" twice, every test of an imported entry succeeds
+func importFixture(t *testing.T) (*mocks.ConnectionRepository, *mocks.ClickHouseClient, *usecase.ConnectionUsecase) {
+	cipher, err := secret.NewCipher([]byte("test-key"))
+	require.NoError(t, err)
+	password, err := cipher.Encrypt("old")
+	require.NoError(t, err)
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindAll", mock.Anything).Return([]*entity.CHConnection{
+		{ID: 1, Name: "staging", Host: "staging.local", Port: 9000},
+		{ID: 2, Name: "prod", Host: "prod.local", Port: 9000, Password: password},
+		{ID: 3, Name: "shared", Host: "a.local", Port: 9000},
+		{ID: 4, Name: "shared", Host: "b.local", Port: 9000},
+	}, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("TestConnection", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		result := args.Get(2).(*entity.ConnectionTestResult)
+		result.Stage = entity.ConnTestStageDone
+		result.ServerVersion = "24.8.1"
+		result.Success = true
+	}).Return().Maybe()
+
+	return repo, chClient, usecase.NewConnectionUsecase(repo, nil, nil, chClient, cipher, entity.QueryOptions{}, 0)
+}
+
+func TestImportConnections(t *testing.T) {
+	repo, _, uc := importFixture(t)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(conn *entity.CHConnection) bool {
+		return conn.Name == "analytics" && conn.ServerInfo == "24.8.1" && conn.Password != "" && conn.Password != "s3cret"
+	})).Return(nil)
+
+	result, err := uc.ImportConnections(context.Background(), &entity.ConnectionProfileFile{
+		Version: entity.ConnectionProfileVersion,
+		Connections: []entity.ConnectionProfile{
+			{Name: "analytics", Host: "analytics.local", Port: 9000, Password: "s3cret"},
+			{Name: "staging", Host: "staging.local", Port: 9000},
+			{Name: "staging", Host: "other.local", Port: 9000},
+			{Name: "shared", Host: "a.local", Port: 9000},
+			{Name: "prod", Host: "prod.local", Port: 9440},
+			{Name: "", Host: "nameless.local", Port: 9000},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	statuses := make([]string, len(result.Entries))
+	for i, entry := range result.Entries {
+		statuses[i] = entry.Status
+	}
+	assert.Equal(t, []string{
+		entity.ImportStatusCreated,
+		entity.ImportStatusUnchanged,
+		entity.ImportStatusConflict,
+		entity.ImportStatusConflict,
+		entity.ImportStatusConflict,
+		entity.ImportStatusFailed,
+	}, statuses)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 3, result.Conflicts)
+	assert.Equal(t, 1, result.Failed)
+}
+
+func TestImportConnectionsOverwrite(t *testing.T) {
+	repo, chClient, uc := importFixture(t)
+	repo.On("Update", mock.Anything, mock.MatchedBy(func(conn *entity.CHConnection) bool {
+		// No password in the file keeps the saved one
+		return conn.ID == 2 && conn.Port == 9440 && conn.Password != ""
+	})).Return(nil)
+	chClient.On("Invalidate", int64(2)).Return()
+
+	result, err := uc.ImportConnections(context.Background(), &entity.ConnectionProfileFile{
+		Version:     entity.ConnectionProfileVersion,
+		Connections: []entity.ConnectionProfile{{Name: "prod", Host: "prod.local", Port: 9440}},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, entity.ImportStatusUpdated, result.Entries[0].Status)
+}
+
+func TestImportConnectionsUnreachable(t *testing.T) {
+	cipher, err := secret.NewCipher([]byte("test-key"))
+	require.NoError(t, err)
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindAll", mock.Anything).Return([]*entity.CHConnection{}, nil)
+
+	// Nothing is saved when the entry cannot be reached
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("TestConnection", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		result := args.Get(2).(*entity.ConnectionTestResult)
+		result.Stage = entity.ConnTestStagePing
+		result.Error = "connection refused"
+	}).Return()
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, cipher, entity.QueryOptions{}, 0)
+	result, err := uc.ImportConnections(context.Background(), &entity.ConnectionProfileFile{
+		Version:     entity.ConnectionProfileVersion,
+		Connections: []entity.ConnectionProfile{{Name: "analytics", Host: "analytics.local", Port: 9000}},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "connection refused", result.Entries[0].Message)
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/helper"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/sqlite"
+)
+
+const (
+	healthCheckTimeout    = 10 * time.Second
+	healthHistoryMaxLimit = 500
+)
+
+type HealthUsecase interface {
+	Run(ctx context.Context, interval time.Duration)
+	CheckAll(ctx context.Context)
+	CheckConnection(ctx context.Context, conn *entity.CHConnection) (*entity.ConnectionHealth, error)
+	GetLatestStatuses(ctx context.Context) (map[int64]*entity.ConnectionHealth, error)
+	GetStatusHistory(ctx context.Context, connectionID int64, limit int) ([]*entity.ConnectionHealth, error)
+}
+
+type healthUsecase struct {
+	healthRepo     sqlite.HealthRepository
+	connectionRepo sqlite.ConnectionRepository
+	chClient       clickhouse.ClickHouseClient
+}
+
+func NewHealthUsecase(
+	healthRepo sqlite.HealthRepository,
+	connectionRepo sqlite.ConnectionRepository,
+	chClient clickhouse.ClickHouseClient,
+) HealthUsecase {
+	return &healthUsecase{
+		healthRepo:     healthRepo,
+		connectionRepo: connectionRepo,
+		chClient:       chClient,
+	}
+}
+
+// Run pings every saved connection on the given interval until ctx is cancelled
+func (u *healthUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	u.CheckAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.CheckAll(ctx)
+		}
+	}
+}
+
+func (u *healthUsecase) CheckAll(ctx context.Context) {
+	funcName := "HealthUsecase.CheckAll"
+
+	conns, err := u.connectionRepo.FindAll(ctx)
+	if err != nil {
+		helper.LogError("HealthCheck", funcName, err, entity.CaptureFields{}, "")
+		return
+	}
+
+	// Unreachable hosts wait for the dial timeout, so check them in parallel
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn *entity.CHConnection) {
+			defer wg.Done()
+			if _, err := u.CheckConnection(ctx, conn); err != nil {
+				helper.LogError("HealthCheck", funcName, err, entity.CaptureFields{
+					"connection_id": helper.ToString(conn.ID),
+				}, "")
+			}
+		}(conn)
+	}
+	wg.Wait()
+}
+
+func (u *healthUsecase) CheckConnection(ctx context.Context, conn *entity.CHConnection) (*entity.ConnectionHealth, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	now := time.Now()
+	health := &entity.ConnectionHealth{
+		ConnectionID: conn.ID,
+		CheckedAt:    now,
+	}
+
+	// A check dials a connection of its own, pinging the cached pool would keep it from ever going idle
+	var result entity.ConnectionTestResult
+	start := time.Now()
+	u.chClient.TestConnection(checkCtx, conn, &result)
+
+	if result.Stage == entity.ConnTestStagePing {
+		health.Status = entity.HealthStatusOffline
+		health.Error = result.Error
+		health.LatencyMs = time.Since(start).Milliseconds()
+	} else {
+		health.Status = entity.HealthStatusOnline
+		health.ServerVersion = result.ServerVersion
+		health.LatencyMs = result.LatencyMs
+	}
+
+	// Carry "since" forward while the status doesn't change
+	health.Since = now
+	previous, err := u.healthRepo.FindLatestByConnectionID(ctx, conn.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.Status == health.Status {
+		health.Since = previous.Since
+	}
+
+	if err := u.healthRepo.Create(ctx, health); err != nil {
+		return nil, err
+	}
+	if err := u.healthRepo.Prune(ctx, conn.ID, healthHistoryMaxLimit); err != nil {
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// GetLatestStatuses returns the last check result keyed by connection ID
+func (u *healthUsecase) GetLatestStatuses(ctx context.Context) (map[int64]*entity.ConnectionHealth, error) {
+	healths, err := u.healthRepo.FindLatestForAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int64]*entity.ConnectionHealth, len(healths))
+	for _, health := range healths {
+		statuses[health.ConnectionID] = health
+	}
+	return statuses, nil
+}
+
+func (u *healthUsecase) GetStatusHistory(ctx context.Context, connectionID int64, limit int) ([]*entity.ConnectionHealth, error) {
+	if limit <= 0 || limit > healthHistoryMaxLimit {
+		limit = 100
+	}
+	return u.healthRepo.FindByConnectionID(ctx, connectionID, limit)
+}
@@ -0,0 +1,139 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+	"github.com/rahmatrdn/go-ch-manager/internal/secret"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/fixture"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConnection(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1, Name: "local"}
+	since := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		previous   *entity.ConnectionHealth
+		pingErr    error
+		wantStatus string
+		keepSince  bool
+	}{
+		{name: "first check", wantStatus: entity.HealthStatusOnline},
+		{name: "still online", previous: &entity.ConnectionHealth{Status: entity.HealthStatusOnline, Since: since}, wantStatus: entity.HealthStatusOnline, keepSince: true},
+		{name: "back online", previous: &entity.ConnectionHealth{Status: entity.HealthStatusOffline, Since: since}, wantStatus: entity.HealthStatusOnline},
+		{name: "goes offline", previous: &entity.ConnectionHealth{Status: entity.HealthStatusOnline, Since: since}, pingErr: errors.New("connection refused"), wantStatus: entity.HealthStatusOffline},
+		{name: "still offline", previous: &entity.ConnectionHealth{Status: entity.HealthStatusOffline, Since: since}, pingErr: errors.New("connection refused"), wantStatus: entity.HealthStatusOffline, keepSince: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chClient := mocks.NewClickHouseClient(t)
+			chClient.On("TestConnection", mock.Anything, conn, mock.Anything).Run(func(args mock.Arguments) {
+				result := args.Get(2).(*entity.ConnectionTestResult)
+				result.Stage = entity.ConnTestStagePing
+				if tt.pingErr != nil {
+					result.Error = tt.pingErr.Error()
+					return
+				}
+				result.Stage, result.Success, result.ServerVersion = entity.ConnTestStageDone, true, "24.8.1"
+			})
+
+			// Every check is stored, then the history is pruned to its limit
+			healthRepo := mocks.NewHealthRepository(t)
+			healthRepo.On("FindLatestByConnectionID", mock.Anything, int64(1)).Return(tt.previous, nil)
+			healthRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			healthRepo.On("Prune", mock.Anything, int64(1), 500).Return(nil)
+
+			uc := usecase.NewHealthUsecase(healthRepo, nil, chClient)
+
+			health, err := uc.CheckConnection(context.Background(), conn)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, health.Status)
+			assert.Equal(t, tt.keepSince, health.Since.Equal(since))
+			if tt.pingErr != nil {
+				assert.Equal(t, "connection refused", health.Error)
+				assert.Empty(t, health.ServerVersion)
+			} else {
+				assert.Equal(t, "24.8.1", health.ServerVersion)
+			}
+		})
+	}
+}
+
+func TestCheckConnectionPruneError(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1}
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("TestConnection", mock.Anything, conn, mock.Anything).Run(func(args mock.Arguments) {
+		result := args.Get(2).(*entity.ConnectionTestResult)
+		result.Stage, result.Error = entity.ConnTestStagePing, "timeout"
+	})
+
+	healthRepo := mocks.NewHealthRepository(t)
+	healthRepo.On("FindLatestByConnectionID", mock.Anything, int64(1)).Return(nil, nil)
+	healthRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	healthRepo.On("Prune", mock.Anything, int64(1), 500).Return(errors.New("database is locked"))
+
+	_, err := usecase.NewHealthUsecase(healthRepo, nil, chClient).CheckConnection(context.Background(), conn)
+	assert.EqualError(t, err, "database is locked")
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	healthRepo := mocks.NewHealthRepository(t)
+	healthRepo.On("FindByConnectionID", mock.Anything, int64(1), 20).Return([]*entity.ConnectionHealth{}, nil).Once()
+	// Missing and oversized limits fall back to the default
+	healthRepo.On("FindByConnectionID", mock.Anything, int64(1), 100).Return([]*entity.ConnectionHealth{}, nil).Twice()
+
+	uc := usecase.NewHealthUsecase(healthRepo, nil, nil)
+	for _, limit := range []int{20, 0, 1000} {
+		_, err := uc.GetStatusHistory(context.Background(), 1, limit)
+		require.NoError(t, err)
+	}
+}
+
+func TestHealthMonitorLetsPoolsIdle(t *testing.T) {
+	server := fixture.NewClickHouseServer(t)
+	conn := server.Connection(1, "default")
+
+	cipher, err := secret.NewCipher([]byte("health-test"))
+	require.NoError(t, err)
+	chClient := clickhouse.NewClickHouseClient(cipher, time.Second)
+	defer chClient.Close()
+
+	connectionRepo := mocks.NewConnectionRepository(t)
+	connectionRepo.On("FindAll", mock.Anything).Return([]*entity.CHConnection{conn}, nil)
+	healthRepo := mocks.NewHealthRepository(t)
+	healthRepo.On("FindLatestByConnectionID", mock.Anything, int64(1)).Return(nil, nil)
+	healthRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	healthRepo.On("Prune", mock.Anything, int64(1), 500).Return(nil)
+
+	// The console opened a pool, the monitor keeps checking the server while nobody uses it
+	require.NoError(t, chClient.Ping(context.Background(), conn))
+	require.Len(t, chClient.PoolStats(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		usecase.NewHealthUsecase(healthRepo, connectionRepo, chClient).Run(ctx, 100*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Eventually(t, func() bool { return len(chClient.PoolStats()) == 0 }, 5*time.Second, 50*time.Millisecond)
+	healthRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(h *entity.ConnectionHealth) bool {
+		return h.Status == entity.HealthStatusOnline
+	}))
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/sqlite"
+)
+
+// A token is about 4 bytes of English or SQL, close enough to keep a prompt under the model's context
+const promptBytesPerToken = 4
+
+// advisorSystemPrompt tells the model what it gets and the shape of the answer parseRecommendations reads
+const advisorSystemPrompt = `You are a ClickHouse performance expert. You get a query with its EXPLAIN plan, the stats of a run, the CREATE statements of the tables it reads and the findings of a linter. The literal values of the query were replaced with ?.
+
+Answer in markdown with one section per recommendation, the most impactful first. Start each section with a line "### [impact] Title", impact being high, medium or low, then explain the recommendation. When it changes the query or a table, end the section with a ` + "```sql" + ` block holding the new query or DDL. Do not repeat the input.`
+
+// Analyzer asks a language model about a query analysis. Analyze passes each piece of the answer to onDelta as it is
+// generated and returns the whole answer, Model names the model answering.
+type Analyzer interface {
+	Model() string
+	Analyze(ctx context.Context, system, prompt string, onDelta func(string)) (*entity.AICompletion, error)
+}
+
+type AdvisorUsecase interface {
+	AdviseQuery(ctx context.Context, id int64, req entity.AIAnalysisRequest, onDelta func(string)) (*entity.AIAnalysis, error)
+}
+
+type advisorUsecase struct {
+	connections     *ConnectionUsecase
+	analyzer        Analyzer
+	cacheRepo       sqlite.AIAnalysisRepository
+	maxPromptTokens int
+	cacheTTL        time.Duration
+}
+
+// NewAdvisorUsecase wires the AI analysis. analyzer is nil when no model is configured, prompts longer than
+// maxPromptTokens are refused (0 for no limit) and answers are cached for cacheTTL (0 keeps them).
+func NewAdvisorUsecase(connections *ConnectionUsecase, analyzer Analyzer, cacheRepo sqlite.AIAnalysisRepository, maxPromptTokens int, cacheTTL time.Duration) AdvisorUsecase {
+	return &advisorUsecase{
+		connections:     connections,
+		analyzer:        analyzer,
+		cacheRepo:       cacheRepo,
+		maxPromptTokens: maxPromptTokens,
+		cacheTTL:        cacheTTL,
+	}
+}
+
+// AdviseQuery analyzes the query and asks the model for recommendations on it, with the literals of the query
+// redacted. The answer is cached per connection, model, mode and normalized query, a cached answer comes back whole
+// without deltas.
+func (u *advisorUsecase) AdviseQuery(ctx context.Context, id int64, req entity.AIAnalysisRequest, onDelta func(string)) (*entity.AIAnalysis, error) {
+	if u.analyzer == nil {
+		return nil, fmt.Errorf("no LLM provider is configured, set LLM_BASE_URL to enable the AI analysis")
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = entity.AnalyzeModeFull
+	}
+	model := u.analyzer.Model()
+	normalized := chsql.Normalize(req.Query)
+	key := cacheKey(id, model, mode, normalized)
+
+	if !req.Refresh {
+		cached, err := u.cacheRepo.FindByCacheKey(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil && (u.cacheTTL == 0 || time.Since(cached.CreatedAt) < u.cacheTTL) {
+			return &entity.AIAnalysis{
+				Model:            cached.Model,
+				Content:          cached.Content,
+				Recommendations:  parseRecommendations(cached.Content),
+				PromptTokens:     cached.PromptTokens,
+				CompletionTokens: cached.CompletionTokens,
+				Truncated:        cached.Truncated,
+				Cached:           true,
+				CreatedAt:        cached.CreatedAt,
+			}, nil
+		}
+	}
+
+	analysis, err := u.connections.AnalyzeQuery(ctx, id, req.AnalyzeRequest)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := u.prompt(analysis)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := u.analyzer.Analyze(ctx, advisorSystemPrompt, prompt, onDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.AIAnalysis{
+		Model:            model,
+		Content:          completion.Content,
+		Recommendations:  parseRecommendations(completion.Content),
+		PromptTokens:     completion.PromptTokens,
+		CompletionTokens: completion.CompletionTokens,
+		Truncated:        completion.Truncated,
+		CreatedAt:        time.Now(),
+	}
+	err = u.cacheRepo.Save(ctx, &entity.AIAnalysisCache{
+		ConnectionID:     id,
+		CacheKey:         key,
+		Model:            model,
+		NormalizedQuery:  normalized,
+		Content:          result.Content,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.CompletionTokens,
+		Truncated:        result.Truncated,
+		CreatedAt:        result.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// prompt writes the analysis text for the model, without the literals of the query nor the EXPLAIN error that may
+// quote them. The CREATE statements are left out when the prompt would not fit the token limit otherwise.
+func (u *advisorUsecase) prompt(analysis *entity.QueryAnalysis) (string, error) {
+	redacted := *analysis
+	redacted.Query = chsql.Redact(analysis.Query)
+	if strings.HasPrefix(redacted.ExplainPlan, "-- Failed") {
+		redacted.ExplainPlan = "-- Failed to get EXPLAIN plan"
+	}
+
+	prompt := u.connections.generateAnalysisText(&redacted)
+	if u.fits(prompt) {
+		return prompt, nil
+	}
+	redacted.Tables = nil
+	prompt = u.connections.generateAnalysisText(&redacted)
+	if u.fits(prompt) {
+		return prompt, nil
+	}
+	return "", fmt.Errorf("the analysis is about %d tokens, over the prompt limit of %d", approxTokens(advisorSystemPrompt+prompt), u.maxPromptTokens)
+}
+
+func (u *advisorUsecase) fits(prompt string) bool {
+	return u.maxPromptTokens == 0 || approxTokens(advisorSystemPrompt+prompt) <= u.maxPromptTokens
+}
+
+func approxTokens(text string) int {
+	return (len(text) + promptBytesPerToken - 1) / promptBytesPerToken
+}
+
+func cacheKey(id int64, model, mode, normalized string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\n%s\n%s\n%s", id, model, mode, normalized)))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	recommendationHeading = regexp.MustCompile(`(?im)^#{2,4}\s*\[(high|medium|low)\]\s*(.+?)\s*$`)
+	sqlBlock              = regexp.MustCompile("(?s)```sql\\s*\\n(.*?)```")
+)
+
+// parseRecommendations reads the "### [impact] Title" sections the system prompt asks for, an answer in any other
+// shape gives none and is only shown as text
+func parseRecommendations(content string) []entity.AIRecommendation {
+	recommendations := make([]entity.AIRecommendation, 0)
+	headings := recommendationHeading.FindAllStringSubmatchIndex(content, -1)
+	for i, h := range headings {
+		end := len(content)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		body := content[h[1]:end]
+
+		r := entity.AIRecommendation{
+			Impact: strings.ToLower(content[h[2]:h[3]]),
+			Title:  content[h[4]:h[5]],
+		}
+		if m := sqlBlock.FindStringSubmatchIndex(body); m != nil {
+			r.SQL = strings.TrimSpace(body[m[2]:m[3]])
+			body = body[:m[0]] + body[m[1]:]
+		}
+		r.Detail = strings.TrimSpace(body)
+		recommendations = append(recommendations, r)
+	}
+	return recommendations
+}
@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const advice = "Most of the table is read.\n\n" +
+	"### [High] Filter on the primary key\n" +
+	"The filter skips tenant_id.\n" +
+	"```sql\nSELECT * FROM analytics.events WHERE tenant_id = ?\n```\n\n" +
+	"### [low] Use LowCardinality\n" +
+	"browser holds few values.\n"
+
+func TestAdviseQuery(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1, Database: "analytics"}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindPlan, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPlan, Text: jsonPlan}, nil)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindEstimate, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindEstimate}, nil)
+	chClient.On("GetCreateSQL", mock.Anything, mock.Anything, "events").Return("CREATE TABLE analytics.events (tenant_id UInt32, email String) ENGINE = MergeTree ORDER BY tenant_id", nil)
+
+	query := "SELECT * FROM analytics.events WHERE email = 'jane@example.com' AND tenant_id = 42"
+	var prompt string
+	analyzer := mocks.NewAnalyzer(t)
+	analyzer.On("Model").Return("local-model")
+	analyzer.On("Analyze", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			prompt = args.String(2)
+			args.Get(3).(func(string))(advice)
+		}).
+		Return(&entity.AICompletion{Content: advice, PromptTokens: 900, CompletionTokens: 80}, nil)
+
+	cacheRepo := mocks.NewAIAnalysisRepository(t)
+	cacheRepo.On("FindByCacheKey", mock.Anything, mock.Anything).Return(nil, nil)
+	cacheRepo.On("Save", mock.Anything, mock.MatchedBy(func(c *entity.AIAnalysisCache) bool {
+		return c.ConnectionID == 1 && c.Model == "local-model" && len(c.CacheKey) == 64 &&
+			c.NormalizedQuery == "SELECT * FROM analytics . events WHERE email = ? AND tenant_id = ?"
+	})).Return(nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+	advisor := usecase.NewAdvisorUsecase(uc, analyzer, cacheRepo, 0, time.Hour)
+
+	var streamed string
+	result, err := advisor.AdviseQuery(context.Background(), 1, entity.AIAnalysisRequest{
+		AnalyzeRequest: entity.AnalyzeRequest{QueryRequest: entity.QueryRequest{Query: query}, Mode: entity.AnalyzeModeDryRun},
+	}, func(delta string) { streamed += delta })
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "WHERE email = ? AND tenant_id = ?")
+	assert.NotContains(t, prompt, "jane@example.com")
+	assert.NotContains(t, prompt, "42")
+	assert.Equal(t, advice, streamed)
+
+	assert.False(t, result.Cached)
+	assert.Equal(t, []entity.AIRecommendation{
+		{Impact: entity.AIImpactHigh, Title: "Filter on the primary key", Detail: "The filter skips tenant_id.", SQL: "SELECT * FROM analytics.events WHERE tenant_id = ?"},
+		{Impact: entity.AIImpactLow, Title: "Use LowCardinality", Detail: "browser holds few values."},
+	}, result.Recommendations)
+}
+
+func TestAdviseQueryCached(t *testing.T) {
+	// Neither the connection nor the model is asked, the answer comes from the cache
+	analyzer := mocks.NewAnalyzer(t)
+	analyzer.On("Model").Return("local-model")
+
+	cacheRepo := mocks.NewAIAnalysisRepository(t)
+	cacheRepo.On("FindByCacheKey", mock.Anything, mock.Anything).
+		Return(&entity.AIAnalysisCache{Model: "local-model", Content: advice, CreatedAt: time.Now().Add(-time.Minute)}, nil)
+
+	advisor := usecase.NewAdvisorUsecase(nil, analyzer, cacheRepo, 0, time.Hour)
+
+	result, err := advisor.AdviseQuery(context.Background(), 1, entity.AIAnalysisRequest{
+		AnalyzeRequest: entity.AnalyzeRequest{QueryRequest: entity.QueryRequest{Query: "SELECT 1"}},
+	}, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Len(t, result.Recommendations, 2)
+
+	_, err = usecase.NewAdvisorUsecase(nil, nil, nil, 0, 0).AdviseQuery(context.Background(), 1, entity.AIAnalysisRequest{}, nil)
+	assert.EqualError(t, err, "no LLM provider is configured, set LLM_BASE_URL to enable the AI analysis")
+}
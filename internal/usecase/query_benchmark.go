@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+const (
+	maxBenchmarkIterations = 100
+	maxBenchmarkWarmUp     = 10
+	// Below this many runs per query the U test cannot tell a difference from noise
+	minVerdictIterations = 5
+	verdictAlpha         = 0.05
+)
+
+// benchmarkRuns checks the run counts of a comparison, a single measured run when none is asked
+func benchmarkRuns(req entity.CompareRequest) (int, int, error) {
+	iterations := req.Iterations
+	if iterations <= 0 {
+		iterations = 1
+	}
+	if iterations > maxBenchmarkIterations {
+		return 0, 0, fmt.Errorf("at most %d iterations per query", maxBenchmarkIterations)
+	}
+	if req.WarmUp < 0 || req.WarmUp > maxBenchmarkWarmUp {
+		return 0, 0, fmt.Errorf("warm-up runs must be between 0 and %d", maxBenchmarkWarmUp)
+	}
+	return iterations, req.WarmUp, nil
+}
+
+// medianRun returns the run with the median duration, the lower one of an even count
+func medianRun(runs []*entity.QueryStats) *entity.QueryStats {
+	if len(runs) == 0 {
+		return nil
+	}
+	sorted := append([]*entity.QueryStats(nil), runs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ExecutionTimeMs < sorted[j].ExecutionTimeMs })
+	return sorted[(len(sorted)-1)/2]
+}
+
+func summarizeRuns(runs []*entity.QueryStats) *entity.BenchmarkStats {
+	n := len(runs)
+	durations := make([]int64, n)
+	var duration, rows, bytes, memory []float64
+	unavailable := 0
+	for i, run := range runs {
+		if run.Unavailable {
+			unavailable++
+		}
+		durations[i] = run.ExecutionTimeMs
+		duration = append(duration, float64(run.ExecutionTimeMs))
+		rows = append(rows, float64(run.RowsRead))
+		bytes = append(bytes, float64(run.BytesRead))
+		memory = append(memory, float64(run.MemoryPeak))
+	}
+
+	return &entity.BenchmarkStats{
+		Iterations:      n,
+		DurationsMs:     durations,
+		UnavailableRuns: unavailable,
+		ExecutionTimeMs: summarize(duration),
+		RowsRead:        summarize(rows),
+		BytesRead:       summarize(bytes),
+		MemoryPeak:      summarize(memory),
+	}
+}
+
+func summarize(values []float64) entity.MetricSummary {
+	if len(values) == 0 {
+		return entity.MetricSummary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return entity.MetricSummary{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P50: percentile(sorted, 50),
+		P95: percentile(sorted, 95),
+	}
+}
+
+// percentile interpolates between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// compareVerdict runs a two-sided Mann-Whitney U test on the durations, it holds up with the skewed timings of a busy server
+func compareVerdict(baseLabel string, base *entity.BenchmarkStats, label string, bench *entity.BenchmarkStats) *entity.CompareVerdict {
+	p1, p2 := base.ExecutionTimeMs.P50, bench.ExecutionTimeMs.P50
+
+	verdict := &entity.CompareVerdict{PValue: 1}
+	if p1 > 0 {
+		verdict.ChangePercent = math.Round((p2-p1)/p1*1000) / 10
+	}
+
+	if base.Iterations < minVerdictIterations || bench.Iterations < minVerdictIterations {
+		verdict.Summary = fmt.Sprintf("Run at least %d iterations per query to tell a real difference from noise", minVerdictIterations)
+		return verdict
+	}
+
+	verdict.PValue = mannWhitneyU(base.DurationsMs, bench.DurationsMs)
+	verdict.Significant = verdict.PValue < verdictAlpha
+	verdict.Faster = p2 < p1
+
+	switch {
+	case !verdict.Significant:
+		verdict.Summary = fmt.Sprintf("%s: no significant difference with %s (p = %.3f)", label, baseLabel, verdict.PValue)
+	case verdict.Faster:
+		verdict.Summary = fmt.Sprintf("%s is %.1f%% faster than %s (p = %.3f)", label, -verdict.ChangePercent, baseLabel, verdict.PValue)
+	default:
+		verdict.Summary = fmt.Sprintf("%s is %.1f%% slower than %s (p = %.3f)", label, verdict.ChangePercent, baseLabel, verdict.PValue)
+	}
+	return verdict
+}
+
+// mannWhitneyU returns the two-sided p-value of the U test, from the normal approximation with tie correction
+func mannWhitneyU(a, b []int64) float64 {
+	type sample struct {
+		value int64
+		first bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range b {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Tied values share the average of their ranks
+	n := float64(len(all))
+	rankSum, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n1, n2 := float64(len(a)), float64(len(b))
+	u := rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+
+	// Continuity correction towards the mean
+	z := math.Max(math.Abs(u-mean)-0.5, 0) / sigma
+	return math.Erfc(z / math.Sqrt2)
+}
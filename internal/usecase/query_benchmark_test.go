@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// timings returns the durations one after the other, the warm-up run included
+func timings(durations ...int64) func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.QueryStats, error) {
+	i := 0
+	return func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.QueryStats, error) {
+		d := durations[i%len(durations)]
+		i++
+		return &entity.QueryStats{ExecutionTimeMs: d, RowsRead: 1000}, nil
+	}
+}
+
+func TestCompareQueriesBenchmark(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	opts := entity.QueryOptions{ReadOnly: true}
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, conn, "SELECT old", opts).
+		Return(timings(900, 120, 100, 110, 105, 130, 115)).Times(7)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, conn, "SELECT new", opts).
+		Return(timings(900, 60, 50, 55, 70, 52, 58)).Times(7)
+	chClient.On("DropCaches", mock.Anything, conn).Return(nil).Times(12)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	result, err := uc.CompareQueries(ctx, 1, entity.CompareRequest{
+		Query1:     "SELECT old",
+		Query2:     "SELECT new",
+		Iterations: 6,
+		WarmUp:     1,
+		DropCaches: true,
+	})
+	require.NoError(t, err)
+
+	baseline, rewrite := result.Candidates[0], result.Candidates[1]
+
+	// The warm-up run is left out
+	assert.Equal(t, []int64{120, 100, 110, 105, 130, 115}, baseline.Benchmark.DurationsMs)
+	assert.Equal(t, entity.MetricSummary{Min: 100, Avg: 113.33333333333333, Max: 130, P50: 112.5, P95: 127.5}, baseline.Benchmark.ExecutionTimeMs)
+	assert.Equal(t, int64(110), result.Query1Stats.ExecutionTimeMs)
+	assert.Equal(t, float64(1000), rewrite.Benchmark.RowsRead.P95)
+
+	assert.Nil(t, baseline.Verdict)
+	assert.True(t, rewrite.Verdict.Significant)
+	assert.True(t, rewrite.Verdict.Faster)
+	assert.Equal(t, -49.8, rewrite.Verdict.ChangePercent)
+	assert.Equal(t, "Query 2 is 49.8% faster than Query 1 (p = 0.005)", rewrite.Verdict.Summary)
+}
+
+func TestCompareQueriesVerdictNeedsIterations(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, conn, mock.Anything, mock.Anything).Return(timings(100, 50))
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	result, err := uc.CompareQueries(context.Background(), 1, entity.CompareRequest{Query1: "SELECT 1", Query2: "SELECT 2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Candidates[0].Benchmark.Iterations)
+	assert.False(t, result.Candidates[1].Verdict.Significant)
+	assert.Contains(t, result.Candidates[1].Verdict.Summary, "at least 5 iterations")
+
+	_, err = uc.CompareQueries(context.Background(), 1, entity.CompareRequest{Query1: "SELECT 1", Query2: "SELECT 2", Iterations: 1000})
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+)
+
+type runningQuery struct {
+	connectionID int64
+	cancel       context.CancelFunc
+	// current is the ID the server runs the query under when it differs, e.g. the statement a script is at
+	current string
+}
+
+// runningQueries tracks the console queries started by this server, so another request can stop them
+type runningQueries struct {
+	mu      sync.Mutex
+	queries map[string]runningQuery
+}
+
+func newRunningQueries() *runningQueries {
+	return &runningQueries{queries: make(map[string]runningQuery)}
+}
+
+func (r *runningQueries) add(queryID string, connectionID int64, cancel context.CancelFunc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.queries[queryID]; ok {
+		return fmt.Errorf("query %s is already running", queryID)
+	}
+	r.queries[queryID] = runningQuery{connectionID: connectionID, cancel: cancel}
+	return nil
+}
+
+func (r *runningQueries) remove(queryID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.queries, queryID)
+}
+
+// setCurrent records the ID of the statement the query runs on the server now
+func (r *runningQueries) setCurrent(queryID, current string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if query, ok := r.queries[queryID]; ok {
+		query.current = current
+		r.queries[queryID] = query
+	}
+}
+
+// cancel stops the query of the connection, false when it is not running here. It returns the ID the server runs
+// the query under, queryID itself unless a statement of its own was recorded.
+func (r *runningQueries) cancel(queryID string, connectionID int64) (string, bool) {
+	r.mu.Lock()
+	query, ok := r.queries[queryID]
+	if ok && query.connectionID == connectionID {
+		delete(r.queries, queryID)
+	}
+	r.mu.Unlock()
+
+	if !ok || query.connectionID != connectionID {
+		return queryID, false
+	}
+	query.cancel()
+	if query.current != "" {
+		return query.current, true
+	}
+	return queryID, true
+}
+
+// ResolveQueryID validates a query ID chosen by the browser, so it can cancel before the first row arrives.
+// An empty one gets a random ID.
+func ResolveQueryID(queryID string) (string, error) {
+	if queryID == "" {
+		return uuid.New().String(), nil
+	}
+	if _, err := uuid.Parse(queryID); err != nil {
+		return "", fmt.Errorf("query_id must be a UUID")
+	}
+	return queryID, nil
+}
+
+// trackQuery derives the context the query runs with, cancelled by CancelQuery or by the returned done func
+func (u *ConnectionUsecase) trackQuery(ctx context.Context, id int64, queryID string) (context.Context, context.CancelFunc, error) {
+	queryCtx, cancel := context.WithCancel(ctx)
+	if err := u.running.add(queryID, id, cancel); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	done := func() {
+		u.running.remove(queryID)
+		cancel()
+	}
+	return queryCtx, done, nil
+}
+
+// CancelQuery stops a console query. A query started here has its execution cancelled (the driver
+// aborts it on the server), and KILL QUERY is sent for the ones started elsewhere, e.g. a previous run.
+// For a script the kill targets the statement it is at.
+func (u *ConnectionUsecase) CancelQuery(ctx context.Context, id int64, queryID string) (*entity.QueryCancelResult, error) {
+	conn, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	if queryID == "" {
+		return nil, fmt.Errorf("query_id is required")
+	}
+
+	serverID, cancelled := u.running.cancel(queryID, id)
+	result := &entity.QueryCancelResult{
+		QueryID:   queryID,
+		Cancelled: cancelled,
+	}
+
+	killed, err := u.chClient.KillQuery(ctx, conn, serverID)
+	if err != nil && !result.Cancelled {
+		return nil, err
+	}
+	result.Killed = killed
+
+	return result, nil
+}
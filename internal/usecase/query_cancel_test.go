@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelQuery(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1, Name: "local"}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	historyRepo.On("Prune", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	// Capture the context the query runs with
+	var queryCtx context.Context
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("QueryRows", mock.Anything, conn, "SELECT sleep(3)", entity.QueryOptions{QueryID: "query-1"}).
+		Run(func(args mock.Arguments) { queryCtx = args.Get(0).(context.Context) }).
+		Return(&fakeRowStream{}, nil)
+	chClient.On("KillQuery", mock.Anything, conn, "query-1").Return(true, nil).Once()
+	chClient.On("KillQuery", mock.Anything, conn, "other").Return(false, nil).Once()
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	_, _, err := uc.OpenQueryCursor(ctx, 1, entity.QueryRequest{QueryID: "query-1", Query: "SELECT sleep(3)"})
+	require.NoError(t, err)
+
+	// The same ID cannot run twice at once
+	_, _, err = uc.OpenQueryCursor(ctx, 1, entity.QueryRequest{QueryID: "query-1", Query: "SELECT sleep(3)"})
+	assert.Error(t, err)
+
+	result, err := uc.CancelQuery(ctx, 1, "query-1")
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.True(t, result.Killed)
+	assert.ErrorIs(t, queryCtx.Err(), context.Canceled)
+
+	// Queries not started here only get KILL QUERY
+	result, err = uc.CancelQuery(ctx, 1, "other")
+	require.NoError(t, err)
+	assert.False(t, result.Cancelled)
+	assert.False(t, result.Killed)
+}
+
+func TestCancelScript(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1, Name: "local"}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	historyRepo.On("Prune", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	// The first statement runs until the script is cancelled
+	statementID := make(chan string, 1)
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecStatement", mock.Anything, conn, "INSERT INTO t SELECT * FROM s", mock.Anything).
+		Run(func(args mock.Arguments) {
+			statementID <- args.Get(3).(entity.QueryOptions).QueryID
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, context.Canceled)
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	done := make(chan *entity.ScriptResult)
+	go func() {
+		result, _ := uc.ExecuteScript(ctx, 1, entity.QueryRequest{QueryID: "script-1", Query: "INSERT INTO t SELECT * FROM s; SELECT 1"}, false)
+		done <- result
+	}()
+
+	// KILL QUERY reaches the statement, not the script ID the server never saw
+	running := <-statementID
+	chClient.On("KillQuery", mock.Anything, conn, running).Return(true, nil).Once()
+
+	result, err := uc.CancelQuery(ctx, 1, "script-1")
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.True(t, result.Killed)
+
+	script := <-done
+	assert.Equal(t, 1, script.Failed)
+	assert.Equal(t, 1, script.Skipped)
+}
+
+func TestResolveQueryID(t *testing.T) {
+	generated, err := usecase.ResolveQueryID("")
+	require.NoError(t, err)
+	assert.NotEmpty(t, generated)
+
+	id, err := usecase.ResolveQueryID("0b5c2f9e-8d0c-4a4e-9a53-4b8cbd1f6a10")
+	require.NoError(t, err)
+	assert.Equal(t, "0b5c2f9e-8d0c-4a4e-9a53-4b8cbd1f6a10", id)
+
+	_, err = usecase.ResolveQueryID("x'; DROP TABLE t")
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+)
+
+// Queries of a single comparison, each one runs every iteration
+const maxCompareCandidates = 8
+
+// compareTarget is a candidate resolved to its connection and options, with its measured runs
+type compareTarget struct {
+	entity.CompareCandidate
+	conn *entity.CHConnection
+	opts entity.QueryOptions
+	runs []*entity.QueryStats
+}
+
+// CompareQueries benchmarks the candidates of req with the parameters and settings they share, every one against the first.
+// A candidate can run on another connection than id, to compare the same query across servers.
+// Each query runs req.WarmUp times unmeasured, then req.Iterations measured times, the candidates taking turns
+// in a rotating order so a change on the server weighs on all of them alike.
+func (u *ConnectionUsecase) CompareQueries(ctx context.Context, id int64, req entity.CompareRequest) (*entity.CompareResult, error) {
+	candidates := req.Candidates
+	if len(candidates) == 0 {
+		candidates = []entity.CompareCandidate{{Query: req.Query1}, {Query: req.Query2}}
+	}
+	if len(candidates) < 2 || len(candidates) > maxCompareCandidates {
+		return nil, fmt.Errorf("compare between 2 and %d queries", maxCompareCandidates)
+	}
+
+	iterations, warmUp, err := benchmarkRuns(req)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := u.compareTargets(ctx, id, req, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	for w := 0; w < warmUp; w++ {
+		for _, t := range targets {
+			if _, err := u.chClient.ExecuteQueryWithStats(ctx, t.conn, t.Query, t.opts); err != nil {
+				return nil, fmt.Errorf("%s: %w", t.Label, err)
+			}
+		}
+	}
+
+	for n := 0; n < iterations; n++ {
+		for k := range targets {
+			t := targets[(k+n)%len(targets)]
+
+			if req.DropCaches {
+				if err := u.chClient.DropCaches(ctx, t.conn); err != nil {
+					return nil, fmt.Errorf("failed to drop the caches of %s: %w", t.conn.Name, err)
+				}
+			}
+
+			stats, err := u.chClient.ExecuteQueryWithStats(ctx, t.conn, t.Query, t.opts)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", t.Label, err)
+			}
+			t.runs = append(t.runs, stats)
+		}
+	}
+
+	result := &entity.CompareResult{Candidates: make([]entity.CandidateResult, len(targets))}
+	for i, t := range targets {
+		result.Candidates[i] = entity.CandidateResult{
+			Label:          t.Label,
+			Query:          t.Query,
+			ConnectionID:   t.conn.ID,
+			ConnectionName: t.conn.Name,
+			ServerInfo:     t.conn.ServerInfo,
+			Stats:          medianRun(t.runs),
+			Benchmark:      summarizeRuns(t.runs),
+		}
+	}
+
+	baseline := &result.Candidates[0]
+	for i := 1; i < len(result.Candidates); i++ {
+		candidate := &result.Candidates[i]
+		candidate.Verdict = compareVerdict(baseline.Label, baseline.Benchmark, candidate.Label, candidate.Benchmark)
+	}
+
+	if req.CheckResults {
+		u.checkResults(ctx, targets, result.Candidates)
+	}
+
+	result.Query1Stats = result.Candidates[0].Stats
+	result.Query2Stats = result.Candidates[1].Stats
+	return result, nil
+}
+
+// compareTargets resolves the connection of every candidate once, checking the settings against each server
+func (u *ConnectionUsecase) compareTargets(ctx context.Context, id int64, req entity.CompareRequest, candidates []entity.CompareCandidate) ([]*compareTarget, error) {
+	type resolved struct {
+		conn     *entity.CHConnection
+		settings map[string]string
+	}
+	conns := make(map[int64]resolved)
+
+	targets := make([]*compareTarget, len(candidates))
+	for i, candidate := range candidates {
+		if candidate.Label == "" {
+			candidate.Label = fmt.Sprintf("Query %d", i+1)
+		}
+		if candidate.ConnectionID == 0 {
+			candidate.ConnectionID = id
+		}
+
+		r, ok := conns[candidate.ConnectionID]
+		if !ok {
+			conn, err := u.repo.FindByID(ctx, candidate.ConnectionID)
+			if err != nil {
+				return nil, err
+			}
+			if conn == nil {
+				return nil, apperr.ErrRecordNotFound()
+			}
+			if req.DropCaches {
+				if err := guardStatements(conn, req.Confirm, "SYSTEM DROP MARK CACHE"); err != nil {
+					return nil, err
+				}
+			}
+			settings, err := u.querySettings(ctx, conn, req.Settings)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", conn.Name, err)
+			}
+			r = resolved{conn: conn, settings: settings}
+			conns[candidate.ConnectionID] = r
+		}
+
+		params, err := bindParameters(candidate.Query, req.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", candidate.Label, err)
+		}
+
+		targets[i] = &compareTarget{
+			CompareCandidate: candidate,
+			conn:             r.conn,
+			// Comparing only reads, the server refuses anything else
+			opts: entity.QueryOptions{Parameters: params, Settings: r.settings, ReadOnly: true},
+		}
+	}
+
+	return targets, nil
+}
+
+// checkResults checksums the rows of every candidate and tells which ones match the baseline, and how the others differ.
+// A failed check is reported on its candidate, the benchmark is worth returning anyway.
+func (u *ConnectionUsecase) checkResults(ctx context.Context, targets []*compareTarget, results []entity.CandidateResult) {
+	for i, t := range targets {
+		columns, err := u.chClient.ResultColumns(ctx, t.conn, t.Query, t.opts)
+		if err != nil {
+			results[i].ChecksumError = err.Error()
+			continue
+		}
+		checksum, err := u.chClient.ResultChecksum(ctx, t.conn, t.Query, t.opts)
+		if err != nil {
+			results[i].ChecksumError = err.Error()
+			continue
+		}
+		results[i].Columns = columns
+		results[i].Checksum = checksum
+	}
+
+	baseline := results[0]
+	if baseline.Checksum == nil {
+		return
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Checksum == nil {
+			continue
+		}
+
+		diff := diffColumns(baseline.Columns, results[i].Columns)
+		same := *results[i].Checksum == *baseline.Checksum && diff == nil
+		results[i].SameResult = &same
+		if same {
+			continue
+		}
+
+		if diff == nil {
+			diff = &entity.ResultDiff{}
+		}
+		diff.RowsDelta = int64(results[i].Checksum.Rows) - int64(baseline.Checksum.Rows)
+		u.sampleDiff(ctx, targets[0], targets[i], diff)
+		results[i].Diff = diff
+	}
+}
@@ -0,0 +1,95 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareQueriesAcrossConnections(t *testing.T) {
+	ctx := context.Background()
+	staging := &entity.CHConnection{ID: 1, Name: "staging", ServerInfo: "24.8"}
+	prod := &entity.CHConnection{ID: 2, Name: "prod", ServerInfo: "23.8"}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(staging, nil).Once()
+	repo.On("FindByID", mock.Anything, int64(2)).Return(prod, nil).Once()
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&entity.QueryStats{ExecutionTimeMs: 10}, nil).Times(5)
+	columns := []entity.TableSchemaColumn{{Name: "a", Type: "UInt64"}}
+	chClient.On("ResultColumns", mock.Anything, mock.Anything, "SELECT b FROM t", mock.Anything).
+		Return(nil, errors.New("unknown column b"))
+	chClient.On("ResultColumns", mock.Anything, staging, "SELECT toString(a) AS a FROM t", mock.Anything).
+		Return([]entity.TableSchemaColumn{{Name: "a", Type: "String"}}, nil)
+	chClient.On("ResultColumns", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(columns, nil)
+	chClient.On("ResultChecksum", mock.Anything, staging, "SELECT a FROM t", mock.Anything).
+		Return(&entity.ResultChecksum{Rows: 3, Hash: "aa"}, nil)
+	chClient.On("ResultChecksum", mock.Anything, prod, "SELECT a FROM t", mock.Anything).
+		Return(&entity.ResultChecksum{Rows: 3, Hash: "aa"}, nil)
+	chClient.On("ResultChecksum", mock.Anything, staging, "SELECT DISTINCT a FROM t", mock.Anything).
+		Return(&entity.ResultChecksum{Rows: 2, Hash: "bb"}, nil)
+	chClient.On("ResultChecksum", mock.Anything, staging, "SELECT toString(a) AS a FROM t", mock.Anything).
+		Return(&entity.ResultChecksum{Rows: 3, Hash: "cc"}, nil)
+	chClient.On("ResultDiff", mock.Anything, staging, "SELECT a FROM t", "SELECT DISTINCT a FROM t", 10, mock.Anything).
+		Return([]map[string]interface{}{{"a": uint64(7)}}, nil)
+	chClient.On("ResultDiff", mock.Anything, staging, "SELECT DISTINCT a FROM t", "SELECT a FROM t", 10, mock.Anything).
+		Return([]map[string]interface{}{}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	result, err := uc.CompareQueries(ctx, 1, entity.CompareRequest{
+		Candidates: []entity.CompareCandidate{
+			{Label: "staging", Query: "SELECT a FROM t"},
+			{Label: "prod", Query: "SELECT a FROM t", ConnectionID: 2},
+			{Query: "SELECT DISTINCT a FROM t"},
+			{Query: "SELECT b FROM t"},
+			{Query: "SELECT toString(a) AS a FROM t"},
+		},
+		Iterations:   1,
+		CheckResults: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 5)
+
+	assert.Equal(t, "prod", result.Candidates[1].ConnectionName)
+	assert.Equal(t, "23.8", result.Candidates[1].ServerInfo)
+	assert.Equal(t, "Query 3", result.Candidates[2].Label)
+
+	assert.Nil(t, result.Candidates[0].SameResult)
+	assert.True(t, *result.Candidates[1].SameResult)
+	assert.Nil(t, result.Candidates[1].Diff)
+
+	// Same columns, the rows are sampled both ways
+	assert.False(t, *result.Candidates[2].SameResult)
+	assert.Equal(t, &entity.ResultDiff{
+		RowsDelta:   -1,
+		MissingRows: []map[string]interface{}{{"a": uint64(7)}},
+		ExtraRows:   []map[string]interface{}{},
+	}, result.Candidates[2].Diff)
+
+	assert.Nil(t, result.Candidates[3].SameResult)
+	assert.Equal(t, "unknown column b", result.Candidates[3].ChecksumError)
+
+	// The value types differ, rows are not sampled
+	assert.False(t, *result.Candidates[4].SameResult)
+	assert.Equal(t, []entity.ColumnMismatch{{Name: "a", BaselineType: "UInt64", Type: "String"}}, result.Candidates[4].Diff.TypeMismatches)
+	assert.NotEmpty(t, result.Candidates[4].Diff.SampleError)
+}
+
+func TestCompareQueriesCandidateCount(t *testing.T) {
+	uc := usecase.NewConnectionUsecase(nil, nil, nil, nil, nil, entity.QueryOptions{}, 0)
+
+	_, err := uc.CompareQueries(context.Background(), 1, entity.CompareRequest{
+		Candidates: []entity.CompareCandidate{{Query: "SELECT 1"}},
+	})
+	assert.EqualError(t, err, "compare between 2 and 8 queries")
+}
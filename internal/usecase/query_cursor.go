@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+)
+
+const (
+	cursorIdleTTL   = 2 * time.Minute
+	maxOpenCursors  = 20
+	streamChunkRows = 200
+	defaultPageSize = 100
+	maxPageSize     = 10000
+)
+
+// queryCursor keeps a running console query open between page requests
+type queryCursor struct {
+	mu           sync.Mutex
+	id           string
+	connectionID int64
+	stream       clickhouse.RowStream
+	cancel       context.CancelFunc
+	pending      []interface{} // row read ahead to know whether another page exists
+	lastUsed     time.Time
+}
+
+func (c *queryCursor) close() {
+	_ = c.stream.Close()
+	c.cancel()
+}
+
+type cursorRegistry struct {
+	mu      sync.Mutex
+	cursors map[string]*queryCursor
+}
+
+func newCursorRegistry() *cursorRegistry {
+	return &cursorRegistry{cursors: make(map[string]*queryCursor)}
+}
+
+func (r *cursorRegistry) add(cursor *queryCursor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep()
+
+	// Every open cursor holds a pool connection, drop the least recently used one
+	if len(r.cursors) >= maxOpenCursors {
+		var oldest *queryCursor
+		for _, c := range r.cursors {
+			if oldest == nil || c.lastUsed.Before(oldest.lastUsed) {
+				oldest = c
+			}
+		}
+		if oldest.mu.TryLock() {
+			delete(r.cursors, oldest.id)
+			oldest.close()
+			oldest.mu.Unlock()
+		}
+	}
+
+	r.cursors[cursor.id] = cursor
+}
+
+// sweep closes cursors nobody asked for within cursorIdleTTL, callers hold r.mu
+func (r *cursorRegistry) sweep() {
+	for id, c := range r.cursors {
+		if time.Since(c.lastUsed) < cursorIdleTTL || !c.mu.TryLock() {
+			continue
+		}
+		delete(r.cursors, id)
+		c.close()
+		c.mu.Unlock()
+	}
+}
+
+// acquire returns the locked cursor, nil when it expired or belongs to another connection
+func (r *cursorRegistry) acquire(id string, connectionID int64) *queryCursor {
+	r.mu.Lock()
+	r.sweep()
+	cursor, ok := r.cursors[id]
+	r.mu.Unlock()
+
+	if !ok || cursor.connectionID != connectionID {
+		return nil
+	}
+
+	cursor.mu.Lock()
+	cursor.lastUsed = time.Now()
+	return cursor
+}
+
+func (r *cursorRegistry) release(cursor *queryCursor) {
+	cursor.lastUsed = time.Now()
+	cursor.mu.Unlock()
+}
+
+// remove forgets the cursor of the connection, the caller closes it
+func (r *cursorRegistry) remove(id string, connectionID int64) *queryCursor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cursor, ok := r.cursors[id]
+	if !ok || cursor.connectionID != connectionID {
+		return nil
+	}
+	delete(r.cursors, id)
+	return cursor
+}
+
+// OpenQueryCursor starts a console query under req.QueryID and returns its meta chunk and cursor ID, rows are read with ReadQueryCursor.
+// Errors surfaced while starting (syntax, unknown table...) are returned here. CancelQuery stops it until the cursor is closed.
+func (u *ConnectionUsecase) OpenQueryCursor(ctx context.Context, id int64, req entity.QueryRequest) (*entity.QueryChunk, string, error) {
+	conn, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if conn == nil {
+		return nil, "", apperr.ErrRecordNotFound()
+	}
+
+	query, err := singleStatement(req.Query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := guardStatements(conn, req.Confirm, query); err != nil {
+		return nil, "", err
+	}
+
+	params, err := bindParameters(query, req.Parameters)
+	if err != nil {
+		return nil, "", err
+	}
+
+	settings, err := u.querySettings(ctx, conn, req.Settings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The query outlives this request while rows are left for the next pages
+	queryCtx, done, err := u.trackQuery(context.Background(), id, req.QueryID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	opts := u.queryOpts
+	opts.QueryID = req.QueryID
+	opts.Parameters = params
+	opts.Settings = settings
+
+	stream, err := u.chClient.QueryRows(queryCtx, conn, query, opts)
+	if err != nil {
+		done()
+		return nil, "", err
+	}
+
+	u.saveHistory(id, settings, query)
+
+	cursor := &queryCursor{
+		id:           uuid.New().String(),
+		connectionID: id,
+		stream:       stream,
+		cancel:       done,
+		lastUsed:     time.Now(),
+	}
+	u.cursors.add(cursor)
+
+	meta := &entity.QueryChunk{
+		Type:    entity.QueryChunkMeta,
+		QueryID: stream.QueryID(),
+		Columns: stream.Columns(),
+	}
+	return meta, cursor.id, nil
+}
+
+// ReadQueryCursor emits up to pageSize rows in chunks followed by an end chunk.
+// The end chunk carries the cursor while more rows are waiting, or the stats once the result is exhausted.
+// When emit fails (the browser went away) the cursor is closed, which cancels the query.
+func (u *ConnectionUsecase) ReadQueryCursor(ctx context.Context, id int64, cursorID string, pageSize int, emit func(*entity.QueryChunk) error) error {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	cursor := u.cursors.acquire(cursorID, id)
+	if cursor == nil {
+		return apperr.ErrRecordNotFound()
+	}
+
+	finish := func() {
+		u.cursors.remove(cursor.id, cursor.connectionID)
+		cursor.close()
+		cursor.mu.Unlock()
+	}
+
+	batch := make([][]interface{}, 0, streamChunkRows)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := emit(&entity.QueryChunk{Type: entity.QueryChunkRows, Rows: batch})
+		batch = make([][]interface{}, 0, streamChunkRows)
+		return err
+	}
+
+	exhausted := false
+	for read := 0; read < pageSize; read++ {
+		values := cursor.pending
+		cursor.pending = nil
+
+		if values == nil {
+			var err error
+			values, err = cursor.stream.Next()
+			if err == io.EOF {
+				exhausted = true
+				break
+			}
+			if err != nil {
+				finish()
+				return err
+			}
+		}
+
+		batch = append(batch, values)
+		if len(batch) == streamChunkRows {
+			if err := flush(); err != nil {
+				finish()
+				return err
+			}
+		}
+	}
+
+	// Read one row ahead so the page can tell whether there is another one
+	if !exhausted {
+		values, err := cursor.stream.Next()
+		switch {
+		case err == io.EOF:
+			exhausted = true
+		case err != nil:
+			finish()
+			return err
+		default:
+			cursor.pending = values
+		}
+	}
+
+	if err := flush(); err != nil {
+		finish()
+		return err
+	}
+
+	if !exhausted {
+		end := &entity.QueryChunk{
+			Type:     entity.QueryChunkEnd,
+			Cursor:   cursor.id,
+			HasMore:  true,
+			RowCount: cursor.stream.RowCount() - 1, // minus the row read ahead
+		}
+		u.cursors.release(cursor)
+		return emit(end)
+	}
+
+	end := &entity.QueryChunk{
+		Type:      entity.QueryChunkEnd,
+		RowCount:  cursor.stream.RowCount(),
+		Truncated: cursor.stream.Truncated(),
+		Stats:     cursor.stream.Stats(ctx),
+	}
+	finish()
+	return emit(end)
+}
+
+// CloseQueryCursor stops a query whose remaining rows are not wanted anymore
+func (u *ConnectionUsecase) CloseQueryCursor(id int64, cursorID string) {
+	cursor := u.cursors.remove(cursorID, id)
+	if cursor == nil {
+		return
+	}
+
+	cursor.mu.Lock()
+	defer cursor.mu.Unlock()
+	cursor.close()
+}
@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRowStream serves a fixed result set
+type fakeRowStream struct {
+	rows   [][]interface{}
+	next   int
+	closed bool
+}
+
+func (s *fakeRowStream) QueryID() string   { return "query-1" }
+func (s *fakeRowStream) Columns() []string { return []string{"n"} }
+func (s *fakeRowStream) RowCount() uint64  { return uint64(s.next) }
+func (s *fakeRowStream) Truncated() bool   { return false }
+func (s *fakeRowStream) Close() error      { s.closed = true; return nil }
+
+func (s *fakeRowStream) Next() ([]interface{}, error) {
+	if s.next >= len(s.rows) {
+		return nil, io.EOF
+	}
+	s.next++
+	return s.rows[s.next-1], nil
+}
+
+func (s *fakeRowStream) Stats(ctx context.Context) *entity.QueryStats {
+	s.closed = true
+	return &entity.QueryStats{ExecutionTimeMs: 7}
+}
+
+func TestQueryCursorPagination(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1, Name: "local"}
+	stream := &fakeRowStream{rows: [][]interface{}{{1}, {2}, {3}}}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	historyRepo.On("Prune", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("QueryRows", mock.Anything, conn, "SELECT n", entity.QueryOptions{MaxResultRows: 10, QueryID: "query-1"}).Return(stream, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{MaxResultRows: 10}, 0)
+
+	meta, cursorID, err := uc.OpenQueryCursor(ctx, 1, entity.QueryRequest{QueryID: "query-1", Query: "SELECT n"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.QueryChunkMeta, meta.Type)
+	assert.Equal(t, []string{"n"}, meta.Columns)
+
+	read := func(pageSize int) []*entity.QueryChunk {
+		var chunks []*entity.QueryChunk
+		err := uc.ReadQueryCursor(ctx, 1, cursorID, pageSize, func(chunk *entity.QueryChunk) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		require.NoError(t, err)
+		return chunks
+	}
+
+	// First page stops with a cursor, the row read ahead is not counted
+	first := read(2)
+	require.Len(t, first, 2)
+	assert.Equal(t, [][]interface{}{{1}, {2}}, first[0].Rows)
+	assert.True(t, first[1].HasMore)
+	assert.Equal(t, cursorID, first[1].Cursor)
+	assert.Equal(t, uint64(2), first[1].RowCount)
+
+	// Second page returns the read ahead row and the stats
+	second := read(2)
+	require.Len(t, second, 2)
+	assert.Equal(t, [][]interface{}{{3}}, second[0].Rows)
+	assert.False(t, second[1].HasMore)
+	assert.Equal(t, uint64(3), second[1].RowCount)
+	assert.Equal(t, int64(7), second[1].Stats.ExecutionTimeMs)
+	assert.True(t, stream.closed)
+
+	// The exhausted cursor is gone
+	err = uc.ReadQueryCursor(ctx, 1, cursorID, 2, func(*entity.QueryChunk) error { return nil })
+	assert.Error(t, err)
+}
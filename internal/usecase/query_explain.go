@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// ExplainQuery runs one kind of EXPLAIN on a console query, with its parameters and settings, and nests the steps
+// it prints into a tree
+func (u *ConnectionUsecase) ExplainQuery(ctx context.Context, id int64, req entity.ExplainRequest) (*entity.ExplainResult, error) {
+	conn, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("connection not found")
+	}
+
+	bound, err := bindParameters(req.Query, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := u.querySettings(ctx, conn, req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = entity.ExplainKindPlan
+	}
+	return u.explain(ctx, conn, kind, req.Query, entity.QueryOptions{Parameters: bound, Settings: settings, ReadOnly: true})
+}
+
+func (u *ConnectionUsecase) explain(ctx context.Context, conn *entity.CHConnection, kind, query string, opts entity.QueryOptions) (*entity.ExplainResult, error) {
+	result, err := u.chClient.ExplainQuery(ctx, conn, kind, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case entity.ExplainKindPlan:
+		tree, err := parsePlan(result.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the EXPLAIN plan: %w", err)
+		}
+		result.Tree = tree
+		result.Text = planText(tree)
+	case entity.ExplainKindPipeline:
+		result.Tree = parseIndented(result.Text, true)
+	case entity.ExplainKindQueryTree:
+		result.Tree = parseIndented(result.Text, false)
+	}
+	return result, nil
+}
+
+// planIndex is an entry of the Indexes of a JSON plan step
+type planIndex struct {
+	Type             string   `json:"Type"`
+	Name             string   `json:"Name"`
+	Keys             []string `json:"Keys"`
+	Condition        string   `json:"Condition"`
+	InitialParts     uint64   `json:"Initial Parts"`
+	SelectedParts    uint64   `json:"Selected Parts"`
+	InitialGranules  uint64   `json:"Initial Granules"`
+	SelectedGranules uint64   `json:"Selected Granules"`
+}
+
+// parsePlan reads the output of EXPLAIN PLAN json = 1, one {"Plan": step} per query of a UNION
+func parsePlan(text string) ([]*entity.ExplainNode, error) {
+	var plans []struct {
+		Plan map[string]json.RawMessage `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(text), &plans); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*entity.ExplainNode, 0, len(plans))
+	for _, p := range plans {
+		node, err := planNode(p.Plan)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func planNode(fields map[string]json.RawMessage) (*entity.ExplainNode, error) {
+	node := &entity.ExplainNode{}
+	for key, raw := range fields {
+		var err error
+		switch key {
+		case "Node Type":
+			err = json.Unmarshal(raw, &node.Name)
+		case "Description":
+			err = json.Unmarshal(raw, &node.Description)
+		case "Indexes":
+			var indexes []planIndex
+			if err = json.Unmarshal(raw, &indexes); err == nil {
+				for _, idx := range indexes {
+					node.Indexes = append(node.Indexes, entity.ExplainIndex(idx))
+				}
+			}
+		case "Plans":
+			var children []map[string]json.RawMessage
+			if err = json.Unmarshal(raw, &children); err == nil {
+				for _, c := range children {
+					child, err := planNode(c)
+					if err != nil {
+						return nil, err
+					}
+					node.Children = append(node.Children, child)
+				}
+			}
+		default:
+			var value interface{}
+			if err = json.Unmarshal(raw, &value); err == nil {
+				if node.Details == nil {
+					node.Details = make(map[string]interface{})
+				}
+				node.Details[key] = value
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("plan step field %q: %w", key, err)
+		}
+	}
+	return node, nil
+}
+
+// planText prints a plan the way EXPLAIN PLAN indexes = 1 does, an index on one line
+func planText(nodes []*entity.ExplainNode) string {
+	var sb strings.Builder
+	var write func(node *entity.ExplainNode, indent string)
+	write = func(node *entity.ExplainNode, indent string) {
+		sb.WriteString(indent + node.Name)
+		if node.Description != "" {
+			sb.WriteString(" (" + node.Description + ")")
+		}
+		sb.WriteString("\n")
+		for _, idx := range node.Indexes {
+			name := idx.Type
+			if idx.Name != "" {
+				name += " " + idx.Name
+			}
+			if len(idx.Keys) > 0 {
+				name += " (" + strings.Join(idx.Keys, ", ") + ")"
+			}
+			sb.WriteString(fmt.Sprintf("%s  Index %s: parts %d/%d, granules %d/%d\n", indent, name,
+				idx.SelectedParts, idx.InitialParts, idx.SelectedGranules, idx.InitialGranules))
+		}
+		for _, child := range node.Children {
+			write(child, indent+"  ")
+		}
+	}
+	for _, node := range nodes {
+		write(node, "")
+	}
+	return sb.String()
+}
+
+// parseIndented nests the lines of a text EXPLAIN by their indentation. A pipeline prints each (Step) followed by
+// its processors, the processors become the children of their step.
+func parseIndented(text string, pipeline bool) []*entity.ExplainNode {
+	type level struct {
+		indent int
+		node   *entity.ExplainNode
+	}
+	var roots []*entity.ExplainNode
+	var stack []level
+	attach := func(node *entity.ExplainNode) {
+		if len(stack) == 0 {
+			roots = append(roots, node)
+			return
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, node)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		node := &entity.ExplainNode{Name: name}
+
+		if pipeline && !strings.HasPrefix(name, "(") {
+			for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+			attach(node)
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		attach(node)
+		stack = append(stack, level{indent: indent, node: node})
+	}
+	return roots
+}
+
+// estimateRead predicts what the query reads without running it. EXPLAIN ESTIMATE is asked first, older servers
+// and engines it does not cover fall back to the parts and granules the indexes of the plan selected.
+func (u *ConnectionUsecase) estimateRead(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions, plan *entity.ExplainResult) *entity.QueryEstimate {
+	if result, err := u.chClient.ExplainQuery(ctx, conn, entity.ExplainKindEstimate, query, opts); err == nil && len(result.Estimates) > 0 {
+		estimate := &entity.QueryEstimate{Source: entity.ExplainKindEstimate, Tables: result.Estimates}
+		for _, e := range result.Estimates {
+			estimate.Rows += e.Rows
+			estimate.Parts += e.Parts
+			estimate.Marks += e.Marks
+		}
+		return estimate
+	}
+	if plan == nil {
+		return nil
+	}
+
+	estimate := &entity.QueryEstimate{Source: entity.ExplainKindPlan}
+	var walk func(nodes []*entity.ExplainNode)
+	walk = func(nodes []*entity.ExplainNode) {
+		for _, node := range nodes {
+			// The indexes apply one after the other, the last one selected what is read
+			if n := len(node.Indexes); n > 0 {
+				last := node.Indexes[n-1]
+				database, table, _ := strings.Cut(node.Description, ".")
+				estimate.Tables = append(estimate.Tables, entity.ExplainEstimate{
+					Database: database, Table: table, Parts: last.SelectedParts, Marks: last.SelectedGranules,
+				})
+				estimate.Parts += last.SelectedParts
+				estimate.Marks += last.SelectedGranules
+			}
+			walk(node.Children)
+		}
+	}
+	walk(plan.Tree)
+
+	if len(estimate.Tables) == 0 {
+		return nil
+	}
+	return estimate
+}
@@ -0,0 +1,203 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonPlan = `[
+  {
+    "Plan": {
+      "Node Type": "Expression",
+      "Description": "(Project names + Projection)",
+      "Plans": [
+        {
+          "Node Type": "ReadFromMergeTree",
+          "Description": "analytics.events",
+          "Read Type": "Default",
+          "Indexes": [
+            {
+              "Type": "PrimaryKey",
+              "Keys": ["tenant_id"],
+              "Condition": "(tenant_id in [1, 1])",
+              "Initial Parts": 10,
+              "Selected Parts": 2,
+              "Initial Granules": 1200,
+              "Selected Granules": 16
+            },
+            {
+              "Type": "Skip",
+              "Name": "idx_browser",
+              "Description": "bloom_filter GRANULARITY 4",
+              "Initial Parts": 2,
+              "Selected Parts": 2,
+              "Initial Granules": 16,
+              "Selected Granules": 16
+            }
+          ]
+        }
+      ]
+    }
+  }
+]`
+
+func TestExplainQueryPlan(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindPlan, "SELECT * FROM analytics.events WHERE tenant_id = 1", entity.QueryOptions{ReadOnly: true}).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPlan, Text: jsonPlan}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	result, err := uc.ExplainQuery(context.Background(), 1, entity.ExplainRequest{Query: "SELECT * FROM analytics.events WHERE tenant_id = 1"})
+	require.NoError(t, err)
+	require.Len(t, result.Tree, 1)
+
+	read := result.Tree[0].Children[0]
+	assert.Equal(t, "ReadFromMergeTree", read.Name)
+	assert.Equal(t, map[string]interface{}{"Read Type": "Default"}, read.Details)
+	assert.Equal(t, entity.ExplainIndex{
+		Type: "PrimaryKey", Keys: []string{"tenant_id"}, Condition: "(tenant_id in [1, 1])",
+		InitialParts: 10, SelectedParts: 2, InitialGranules: 1200, SelectedGranules: 16,
+	}, read.Indexes[0])
+	assert.Equal(t, "idx_browser", read.Indexes[1].Name)
+
+	assert.Equal(t, `Expression ((Project names + Projection))
+  ReadFromMergeTree (analytics.events)
+    Index PrimaryKey (tenant_id): parts 2/10, granules 16/1200
+    Index Skip idx_browser: parts 2/2, granules 16/16
+`, result.Text)
+}
+
+func TestExplainQueryPipeline(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindPipeline, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPipeline, Text: `(Expression)
+ExpressionTransform × 4
+  (Aggregating)
+  Resize 4 → 4
+    AggregatingTransform × 4
+      (ReadFromMergeTree)
+      MergeTreeSelect(pool: ReadPool, algorithm: Thread) × 4 0 → 1
+`}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	result, err := uc.ExplainQuery(context.Background(), 1, entity.ExplainRequest{Query: "SELECT count() FROM t GROUP BY x", Kind: entity.ExplainKindPipeline})
+	require.NoError(t, err)
+
+	names := func(nodes []*entity.ExplainNode) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.Name)
+		}
+		return out
+	}
+	require.Len(t, result.Tree, 1)
+	expression := result.Tree[0]
+	assert.Equal(t, []string{"ExpressionTransform × 4", "(Aggregating)"}, names(expression.Children))
+
+	aggregating := expression.Children[1]
+	assert.Equal(t, []string{"Resize 4 → 4", "AggregatingTransform × 4", "(ReadFromMergeTree)"}, names(aggregating.Children))
+	assert.Equal(t, []string{"MergeTreeSelect(pool: ReadPool, algorithm: Thread) × 4 0 → 1"}, names(aggregating.Children[2].Children))
+}
+
+func TestAnalyzeQueryDryRun(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1, Database: "analytics"}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	// ExecuteQueryWithStats is not expected, the mock fails the test if the query runs
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindPlan, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPlan, Text: jsonPlan}, nil)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindEstimate, mock.Anything, mock.Anything).
+		Return(nil, errors.New("EXPLAIN ESTIMATE is not supported"))
+	chClient.On("GetCreateSQL", mock.Anything, mock.Anything, "events").Return("CREATE TABLE analytics.events (tenant_id UInt32) ENGINE = MergeTree ORDER BY tenant_id", nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	analysis, err := uc.AnalyzeQuery(context.Background(), 1, entity.AnalyzeRequest{
+		QueryRequest: entity.QueryRequest{Query: "SELECT * FROM analytics.events WHERE tenant_id = 1"},
+		Mode:         entity.AnalyzeModeDryRun,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, analysis.QueryStats)
+	assert.Equal(t, &entity.QueryEstimate{
+		Source: entity.ExplainKindPlan,
+		Parts:  2,
+		Marks:  16,
+		Tables: []entity.ExplainEstimate{{Database: "analytics", Table: "events", Parts: 2, Marks: 16}},
+	}, analysis.Estimate)
+	assert.Contains(t, analysis.AnalysisText, "_Dry run, the query was not executed_")
+
+	_, err = uc.AnalyzeQuery(context.Background(), 1, entity.AnalyzeRequest{Mode: "everything"})
+	assert.EqualError(t, err, `unknown analyze mode "everything"`)
+}
+
+func TestAnalyzeQuerySampled(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1, Database: "analytics"}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindPlan, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPlan, Text: jsonPlan}, nil)
+	chClient.On("ExplainQuery", mock.Anything, conn, entity.ExplainKindEstimate, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindEstimate, Estimates: []entity.ExplainEstimate{
+			{Database: "analytics", Table: "events", Parts: 2, Rows: 130000, Marks: 16},
+		}}, nil)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, conn, mock.Anything, entity.QueryOptions{ReadOnly: true, MaxRowsToRead: 50000, MaxExecutionTime: 10}).
+		Return(&entity.QueryStats{ExecutionTimeMs: 40, RowsRead: 65536}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	analysis, err := uc.AnalyzeQuery(context.Background(), 1, entity.AnalyzeRequest{
+		QueryRequest: entity.QueryRequest{Query: "SELECT 1"},
+		Mode:         entity.AnalyzeModeSampled,
+		SampleRows:   50000,
+	})
+	require.NoError(t, err)
+	assert.True(t, analysis.QueryStats.Partial)
+	assert.Equal(t, uint64(10), analysis.SampleSeconds)
+	assert.Equal(t, uint64(130000), analysis.Estimate.Rows)
+	assert.Contains(t, analysis.AnalysisText, "_Sampled run stopped at 50.000 rows or 10 s")
+}
+
+func TestAnalyzeQuerySampledWithoutStats(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1, Database: "analytics"}
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExplainQuery", mock.Anything, conn, mock.Anything, mock.Anything, mock.Anything).
+		Return(&entity.ExplainResult{Kind: entity.ExplainKindPlan, Text: jsonPlan}, nil)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, conn, mock.Anything, mock.Anything).
+		Return(&entity.QueryStats{ExecutionTimeMs: 40, Unavailable: true}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	// No query_log entry means no rows read to compare with the limit, the run may have been cut off
+	analysis, err := uc.AnalyzeQuery(context.Background(), 1, entity.AnalyzeRequest{
+		QueryRequest: entity.QueryRequest{Query: "SELECT 1"},
+		Mode:         entity.AnalyzeModeSampled,
+	})
+	require.NoError(t, err)
+	assert.True(t, analysis.QueryStats.Partial)
+	assert.Contains(t, analysis.AnalysisText, "whether it stopped early is unknown")
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/export"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// QueryExport is a started result download, the file is written by Stream
+type QueryExport struct {
+	Filename    string
+	ContentType string
+
+	format export.Format
+	stream clickhouse.RowStream // rows encoded locally
+	body   io.ReadCloser        // file written by the server
+	cancel context.CancelFunc
+	max    uint64
+}
+
+// OpenQueryExport re-runs a console query for a download in one of the export formats.
+// Errors surfaced while starting are returned here, before the response is committed to a file.
+func (u *ConnectionUsecase) OpenQueryExport(ctx context.Context, id int64, req entity.QueryRequest, format string) (*QueryExport, error) {
+	f, err := export.Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	query, err := singleStatement(req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := guardStatements(conn, req.Confirm, query); err != nil {
+		return nil, err
+	}
+
+	params, err := bindParameters(query, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := u.querySettings(ctx, conn, req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	opts := entity.QueryOptions{Parameters: params, Settings: settings}
+
+	// The query outlives this request, it runs while the file is downloaded
+	queryCtx, cancel := context.WithCancel(context.Background())
+
+	e := &QueryExport{
+		Filename:    exportFilename(conn.Name, f),
+		ContentType: f.ContentType,
+		format:      f,
+		cancel:      cancel,
+		max:         u.exportMax,
+	}
+
+	if f.ServerFormat != "" {
+		// The server refuses a result over the limit, a file it wrote is never cut
+		opts.MaxResultBytes = u.exportMax
+		e.body, err = u.chClient.ExportQuery(queryCtx, conn, query, f.ServerFormat, opts)
+	} else {
+		// Downloads are not held to the console row limit, only to the file size
+		e.stream, err = u.chClient.QueryRows(queryCtx, conn, query, opts)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Stream writes the file and closes the export, export.ErrLimitReached is returned when it stopped at the maximum size.
+// Locally encoded formats stop on a row boundary, so a cut file is still complete up to its last row. Files written by
+// the server are sent whole, OpenQueryExport already failed when they are over the limit.
+func (e *QueryExport) Stream(w io.Writer) error {
+	defer e.Close()
+
+	if e.body != nil {
+		_, err := io.Copy(w, e.body)
+		return err
+	}
+
+	counter := &countingWriter{w: w}
+	encoder, err := export.NewEncoder(e.format, counter, e.stream.Columns())
+	if err != nil {
+		return err
+	}
+
+	var limitErr error
+	for {
+		if e.max > 0 && counter.n >= e.max {
+			limitErr = export.ErrLimitReached
+			break
+		}
+
+		values, err := e.stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := encoder.WriteRow(values); err != nil {
+			if err == export.ErrLimitReached {
+				limitErr = err
+				break
+			}
+			return err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return limitErr
+}
+
+// Close stops the query, it is safe to call after Stream
+func (e *QueryExport) Close() {
+	if e.stream != nil {
+		_ = e.stream.Close()
+	}
+	if e.body != nil {
+		_ = e.body.Close()
+	}
+	e.cancel()
+}
+
+func exportFilename(connectionName string, f export.Format) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(connectionName, "-"), "-")
+	if name == "" {
+		name = "query"
+	}
+	return fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), f.Extension)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+	"github.com/rahmatrdn/go-ch-manager/internal/helper"
+)
+
+// guardStatements refuses statements that change the server on a PRODUCTION connection until the caller confirms them.
+// Confirmed statements run as asked, each override is logged.
+func guardStatements(conn *entity.CHConnection, confirmed bool, queries ...string) error {
+	funcName := "ConnectionUsecase.guardStatements"
+
+	if !conn.IsProduction() {
+		return nil
+	}
+
+	var (
+		blocked []string
+		kinds   []string
+	)
+	for _, query := range queries {
+		if kind := chsql.Classify(query); kind != chsql.KindRead {
+			blocked = append(blocked, query)
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	if !confirmed {
+		described := make([]string, len(blocked))
+		for i, query := range blocked {
+			described[i] = fmt.Sprintf("%s (%s)", chsql.FirstKeyword(query), kinds[i])
+		}
+		return apperr.ErrConfirmationRequired(fmt.Sprintf("%s is a PRODUCTION connection, confirm to run %s", conn.Name, strings.Join(described, ", ")))
+	}
+
+	for i, query := range blocked {
+		helper.LogWarn("QueryGuard", funcName, fmt.Errorf("%s statement confirmed on a PRODUCTION connection", kinds[i]), entity.CaptureFields{
+			"connection_id": strconv.FormatInt(conn.ID, 10),
+			"connection":    conn.Name,
+			"query":         query,
+		}, "production guard overridden")
+	}
+	return nil
+}
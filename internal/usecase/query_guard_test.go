@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductionGuard(t *testing.T) {
+	ctx := context.Background()
+	prod := &entity.CHConnection{ID: 1, Name: "prod", Label: entity.LabelProduction}
+	dev := &entity.CHConnection{ID: 2, Name: "dev", Label: entity.LabelDevelopment}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(prod, nil)
+	repo.On("FindByID", mock.Anything, int64(2)).Return(dev, nil)
+
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	historyRepo.On("Prune", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithResults", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&entity.QueryResult{}, nil)
+	chClient.On("ExecuteQueryWithStats", mock.Anything, prod, mock.Anything, entity.QueryOptions{ReadOnly: true}).
+		Return(&entity.QueryStats{}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	// Reads run on PRODUCTION as usual
+	_, err := uc.ExecuteQuery(ctx, 1, entity.QueryRequest{QueryID: "q-1", Query: "SELECT 1"})
+	require.NoError(t, err)
+
+	_, err = uc.ExecuteQuery(ctx, 1, entity.QueryRequest{QueryID: "q-2", Query: "DROP TABLE events"})
+	var appErr apperr.CustomErrorResponse
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, entity.CONFIRMATION_CODE, appErr.ErrCode)
+	assert.Contains(t, appErr.Message, "DROP (ddl)")
+
+	_, err = uc.ExecuteQuery(ctx, 1, entity.QueryRequest{QueryID: "q-3", Query: "DROP TABLE events", Confirm: true})
+	require.NoError(t, err)
+
+	// A single write in a script asks for the whole script
+	_, err = uc.ExecuteScript(ctx, 1, entity.QueryRequest{QueryID: "q-4", Query: "SELECT 1; ALTER TABLE events DELETE WHERE 1"}, true)
+	assert.ErrorAs(t, err, &appErr)
+
+	// Other labels are not guarded
+	_, err = uc.ExecuteQuery(ctx, 2, entity.QueryRequest{QueryID: "q-5", Query: "DROP TABLE events"})
+	require.NoError(t, err)
+
+	// Comparisons always run read-only
+	_, err = uc.CompareQueries(ctx, 1, entity.CompareRequest{Query1: "SELECT 1", Query2: "SELECT 2"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// QueryParameters lists the {name:Type} placeholders of a query, the console renders an input for each one
+func (u *ConnectionUsecase) QueryParameters(query string) []chsql.Parameter {
+	params := chsql.Parameters(query)
+	if params == nil {
+		return []chsql.Parameter{}
+	}
+	return params
+}
+
+// bindParameters keeps the values of the placeholders the query uses, every placeholder needs one.
+// Values are sent as text, the server parses them into the placeholder type.
+func bindParameters(query string, values map[string]string) (map[string]string, error) {
+	params := chsql.Parameters(query)
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	bound := make(map[string]string, len(params))
+	var missing []string
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("{%s:%s}", param.Name, param.Type))
+			continue
+		}
+		bound[param.Name] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing value for query parameter %s", strings.Join(missing, ", "))
+	}
+	return bound, nil
+}
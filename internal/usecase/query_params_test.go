@@ -0,0 +1,49 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteQueryParameters(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1}
+	query := "SELECT * FROM events WHERE date = {day:Date} AND user_id = {user:UInt64}"
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	historyRepo.On("Prune", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	// Values of placeholders the query does not use are left out
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithResults", mock.Anything, conn, query, entity.QueryOptions{
+		QueryID:    "query-1",
+		Parameters: map[string]string{"day": "2024-05-01", "user": "42"},
+	}).Return(&entity.QueryResult{QueryID: "query-1"}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	_, err := uc.ExecuteQuery(ctx, 1, entity.QueryRequest{
+		QueryID:    "query-1",
+		Query:      query,
+		Parameters: map[string]string{"day": "2024-05-01", "user": "42", "unused": "x"},
+	})
+	require.NoError(t, err)
+
+	_, err = uc.ExecuteQuery(ctx, 1, entity.QueryRequest{
+		QueryID:    "query-2",
+		Query:      query,
+		Parameters: map[string]string{"day": "2024-05-01"},
+	})
+	assert.EqualError(t, err, "missing value for query parameter {user:UInt64}")
+}
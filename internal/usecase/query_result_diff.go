@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// Differing rows returned each way, enough to spot the pattern
+const resultDiffSample = 10
+
+// diffColumns matches the columns of a result with the baseline ones by name, nil when they are the same
+func diffColumns(baseline, columns []entity.TableSchemaColumn) *entity.ResultDiff {
+	types := make(map[string]string, len(columns))
+	for _, col := range columns {
+		types[col.Name] = col.Type
+	}
+	baseTypes := make(map[string]string, len(baseline))
+	for _, col := range baseline {
+		baseTypes[col.Name] = col.Type
+	}
+
+	diff := &entity.ResultDiff{}
+	for _, col := range baseline {
+		typ, ok := types[col.Name]
+		switch {
+		case !ok:
+			diff.MissingColumns = append(diff.MissingColumns, col.Name)
+		case typ != col.Type:
+			diff.TypeMismatches = append(diff.TypeMismatches, entity.ColumnMismatch{Name: col.Name, BaselineType: col.Type, Type: typ})
+		}
+	}
+	for _, col := range columns {
+		if _, ok := baseTypes[col.Name]; !ok {
+			diff.ExtraColumns = append(diff.ExtraColumns, col.Name)
+		}
+	}
+
+	if len(diff.MissingColumns) == 0 && len(diff.ExtraColumns) == 0 && len(diff.TypeMismatches) == 0 {
+		for i := range baseline {
+			if i >= len(columns) || baseline[i].Name != columns[i].Name {
+				diff.ColumnOrderDiffers = true
+				break
+			}
+		}
+		if !diff.ColumnOrderDiffers {
+			return nil
+		}
+	}
+	return diff
+}
+
+// sampleDiff fills diff with rows returned by only one of the queries.
+// The server matches the rows column by column, so both queries must run on the same connection
+// with the same column types in the same order. A row repeated a different number of times is not sampled.
+func (u *ConnectionUsecase) sampleDiff(ctx context.Context, baseline, target *compareTarget, diff *entity.ResultDiff) {
+	switch {
+	case baseline.conn.ID != target.conn.ID:
+		diff.SampleError = "rows are only sampled between queries of the same connection"
+		return
+	case len(diff.MissingColumns) > 0 || len(diff.ExtraColumns) > 0 || len(diff.TypeMismatches) > 0 || diff.ColumnOrderDiffers:
+		diff.SampleError = "rows are only sampled between results with the same columns"
+		return
+	}
+
+	// Both queries run as one, with the parameters of either
+	opts := target.opts
+	opts.Parameters = make(map[string]string, len(baseline.opts.Parameters)+len(target.opts.Parameters))
+	for name, value := range baseline.opts.Parameters {
+		opts.Parameters[name] = value
+	}
+	for name, value := range target.opts.Parameters {
+		opts.Parameters[name] = value
+	}
+
+	missing, err := u.chClient.ResultDiff(ctx, target.conn, baseline.Query, target.Query, resultDiffSample, opts)
+	if err != nil {
+		diff.SampleError = err.Error()
+		return
+	}
+	extra, err := u.chClient.ResultDiff(ctx, target.conn, target.Query, baseline.Query, resultDiffSample, opts)
+	if err != nil {
+		diff.SampleError = err.Error()
+		return
+	}
+	diff.MissingRows, diff.ExtraRows = missing, extra
+}
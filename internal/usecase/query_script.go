@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
+	"github.com/rahmatrdn/go-ch-manager/internal/chsql"
+)
+
+// Rows kept per statement of a script, the console only shows a preview of each result
+const scriptPreviewRows = 100
+
+// singleStatement drops the trailing semicolon of a console query, several statements have to run as a script
+func singleStatement(query string) (string, error) {
+	statements := chsql.Split(query)
+	switch len(statements) {
+	case 0:
+		// Nothing but comments, the server reports it
+		return query, nil
+	case 1:
+		return statements[0].Query, nil
+	default:
+		return "", fmt.Errorf("the query holds %d statements, run it as a script", len(statements))
+	}
+}
+
+// ExecuteScript runs the statements of the req.Query script one after the other, req.QueryID stops the whole script through CancelQuery.
+// With stopOnError the statements following a failed one are skipped, otherwise they still run.
+// On a PRODUCTION connection a script changing the server only runs once req.Confirm is set. USE and SET are refused,
+// what they change would not reach the statements after them.
+// Every executed statement is recorded in the history.
+func (u *ConnectionUsecase) ExecuteScript(ctx context.Context, id int64, req entity.QueryRequest, stopOnError bool) (*entity.ScriptResult, error) {
+	statements := chsql.Split(req.Query)
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("the script holds no statement")
+	}
+
+	// Each statement may run on another connection of the pool, the session it changes is gone for the next one
+	for _, statement := range statements {
+		switch chsql.FirstKeyword(statement.Query) {
+		case "USE":
+			return nil, fmt.Errorf("line %d: USE does not carry over to the next statements of a script, qualify the table names instead", statement.Line)
+		case "SET":
+			return nil, fmt.Errorf("line %d: SET does not carry over to the next statements of a script, pass it in the script settings instead", statement.Line)
+		}
+	}
+
+	conn, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return nil, apperr.ErrRecordNotFound()
+	}
+
+	// Placeholders are checked for the whole script before anything runs
+	if _, err := bindParameters(req.Query, req.Parameters); err != nil {
+		return nil, err
+	}
+
+	queries := make([]string, len(statements))
+	for i, statement := range statements {
+		queries[i] = statement.Query
+	}
+	if err := guardStatements(conn, req.Confirm, queries...); err != nil {
+		return nil, err
+	}
+
+	settings, err := u.querySettings(ctx, conn, req.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptCtx, done, err := u.trackQuery(ctx, id, req.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	opts := u.queryOpts
+	opts.Settings = settings
+	if opts.MaxResultRows == 0 || opts.MaxResultRows > scriptPreviewRows {
+		opts.MaxResultRows = scriptPreviewRows
+	}
+
+	result := &entity.ScriptResult{
+		QueryID:    req.QueryID,
+		Statements: make([]entity.StatementResult, 0, len(statements)),
+	}
+	executed := make([]string, 0, len(statements))
+	stopped := false
+
+	for i, statement := range statements {
+		res := entity.StatementResult{
+			Index: i + 1,
+			Line:  statement.Line,
+			Query: statement.Query,
+		}
+
+		if stopped {
+			res.Status = entity.StatementSkipped
+			result.Skipped++
+			result.Statements = append(result.Statements, res)
+			continue
+		}
+
+		// Each statement gets its own ID, the stats are looked up by it. CancelQuery finds it under the script's.
+		opts.QueryID = uuid.New().String()
+		u.running.setCurrent(req.QueryID, opts.QueryID)
+		opts.Parameters, _ = bindParameters(statement.Query, req.Parameters)
+		executed = append(executed, statement.Query)
+
+		var err error
+		if chsql.ReturnsRows(statement.Query) {
+			res.Result, err = u.chClient.ExecuteQueryWithResults(scriptCtx, conn, statement.Query, opts)
+			if err == nil {
+				res.Stats, res.Result.Stats = res.Result.Stats, nil
+				// The server stops on a block boundary, which can be well past the preview
+				if len(res.Result.Rows) > scriptPreviewRows {
+					res.Result.Rows = res.Result.Rows[:scriptPreviewRows]
+					res.Result.Truncated = true
+				}
+			}
+		} else {
+			res.Stats, err = u.chClient.ExecStatement(scriptCtx, conn, statement.Query, opts)
+		}
+
+		if err != nil {
+			res.Status = entity.StatementError
+			res.Result = nil
+			res.Error = err.Error()
+			result.Failed++
+			// A cancelled script stops whatever the mode
+			stopped = stopOnError || scriptCtx.Err() != nil
+		} else {
+			res.Status = entity.StatementSuccess
+			result.Succeeded++
+		}
+		result.Statements = append(result.Statements, res)
+	}
+
+	u.saveHistory(id, settings, executed...)
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const script = `CREATE TABLE t (n UInt8) ENGINE = Memory;
+INSERT INTO t VALUES (1), (2);
+SELECT 'a;b' AS s`
+
+func TestExecuteScript(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1, Name: "local"}
+
+	tests := []struct {
+		name        string
+		stopOnError bool
+		want        []string
+		history     int
+	}{
+		{name: "stop on error", stopOnError: true, want: []string{entity.StatementSuccess, entity.StatementError, entity.StatementSkipped}, history: 2},
+		{name: "continue", stopOnError: false, want: []string{entity.StatementSuccess, entity.StatementError, entity.StatementSuccess}, history: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewConnectionRepository(t)
+			repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+			saved := make(chan string, 3)
+			historyRepo := mocks.NewQueryHistoryRepository(t)
+			historyRepo.On("Create", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { saved <- args.Get(1).(*entity.QueryHistory).Query }).
+				Return(nil)
+			historyRepo.On("Prune", mock.Anything, int64(1), 50).Return(nil).Maybe()
+
+			chClient := mocks.NewClickHouseClient(t)
+			chClient.On("ExecStatement", mock.Anything, conn, "CREATE TABLE t (n UInt8) ENGINE = Memory", mock.Anything).
+				Return(&entity.QueryStats{ExecutionTimeMs: 1}, nil)
+			chClient.On("ExecStatement", mock.Anything, conn, "INSERT INTO t VALUES (1), (2)", mock.Anything).
+				Return(nil, errors.New("table is read only"))
+			chClient.On("ExecuteQueryWithResults", mock.Anything, conn, "SELECT 'a;b' AS s", mock.Anything).
+				Return(&entity.QueryResult{Columns: []string{"s"}, Rows: []map[string]interface{}{{"s": "a;b"}}, Stats: &entity.QueryStats{}}, nil).Maybe()
+
+			uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+			result, err := uc.ExecuteScript(ctx, 1, entity.QueryRequest{QueryID: "script-1", Query: script}, tt.stopOnError)
+			require.NoError(t, err)
+
+			statuses := make([]string, 0, len(result.Statements))
+			for _, statement := range result.Statements {
+				statuses = append(statuses, statement.Status)
+			}
+			assert.Equal(t, tt.want, statuses)
+			assert.Equal(t, 2, result.Statements[1].Line)
+			assert.Equal(t, "table is read only", result.Statements[1].Error)
+
+			// History keeps the executed statements in order
+			for i := 0; i < tt.history; i++ {
+				assert.Equal(t, result.Statements[i].Query, <-saved)
+			}
+		})
+	}
+}
+
+func TestExecuteQueryRejectsScripts(t *testing.T) {
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(&entity.CHConnection{ID: 1}, nil)
+
+	uc := usecase.NewConnectionUsecase(repo, nil, nil, nil, nil, entity.QueryOptions{}, 0)
+
+	_, err := uc.ExecuteQuery(context.Background(), 1, entity.QueryRequest{QueryID: "query-1", Query: "SELECT 1; SELECT 2"})
+	assert.ErrorContains(t, err, "2 statements")
+}
+
+func TestExecuteScriptRejectsSessionStatements(t *testing.T) {
+	uc := usecase.NewConnectionUsecase(nil, nil, nil, nil, nil, entity.QueryOptions{}, 0)
+
+	_, err := uc.ExecuteScript(context.Background(), 1, entity.QueryRequest{Query: "SELECT 1;\nUSE logs;\nSELECT * FROM requests"}, false)
+	assert.EqualError(t, err, "line 2: USE does not carry over to the next statements of a script, qualify the table names instead")
+
+	_, err = uc.ExecuteScript(context.Background(), 1, entity.QueryRequest{Query: "SET max_threads = 1; SELECT 1"}, false)
+	assert.EqualError(t, err, "line 1: SET does not carry over to the next statements of a script, pass it in the script settings instead")
+}
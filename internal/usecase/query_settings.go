@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// querySettings checks per-execution overrides against system.settings of the connection: every name has to exist,
+// be changeable by the connection user and hold a value of its type. Enum and string settings are left to the server.
+func (u *ConnectionUsecase) querySettings(ctx context.Context, conn *entity.CHConnection, settings map[string]string) (map[string]string, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	known, err := u.chClient.GetSettings(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the server settings: %w", err)
+	}
+	byName := make(map[string]entity.CHSetting, len(known))
+	for _, setting := range known {
+		byName[setting.Name] = setting
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	validated := make(map[string]string, len(settings))
+	var problems []string
+	for _, name := range names {
+		value := strings.TrimSpace(settings[name])
+		setting, ok := byName[strings.TrimSpace(name)]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown setting %q", name))
+		case setting.Readonly != 0:
+			problems = append(problems, fmt.Sprintf("setting %q cannot be changed", name))
+		default:
+			if err := checkSettingValue(setting.Type, value); err != nil {
+				problems = append(problems, fmt.Sprintf("setting %q: %v", name, err))
+				continue
+			}
+			validated[setting.Name] = value
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid settings: %s", strings.Join(problems, "; "))
+	}
+	return validated, nil
+}
+
+// checkSettingValue parses value as the system.settings type
+func checkSettingValue(typ, value string) error {
+	var err error
+	switch typ {
+	case "UInt64", "UInt32", "NonZeroUInt64":
+		var n uint64
+		n, err = strconv.ParseUint(value, 10, 64)
+		if err == nil && n == 0 && typ == "NonZeroUInt64" {
+			return errors.New("must not be 0")
+		}
+	case "Int64", "Int32":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "Float", "Double":
+		_, err = strconv.ParseFloat(value, 64)
+	case "Seconds", "Milliseconds":
+		var n float64
+		n, err = strconv.ParseFloat(value, 64)
+		if err == nil && n < 0 {
+			return errors.New("must not be negative")
+		}
+	case "Bool":
+		switch strings.ToLower(value) {
+		case "0", "1", "true", "false":
+		default:
+			return errors.New("expected 0, 1, true or false")
+		}
+	case "MaxThreads", "UInt64Auto":
+		if strings.HasPrefix(strings.ToLower(value), "auto") {
+			return nil
+		}
+		_, err = strconv.ParseUint(value, 10, 64)
+	}
+
+	if err != nil {
+		return fmt.Errorf("expected a %s value, got %q", typ, value)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySettings(t *testing.T) {
+	ctx := context.Background()
+	conn := &entity.CHConnection{ID: 1}
+
+	repo := mocks.NewConnectionRepository(t)
+	repo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	saved := make(chan *entity.QueryHistory, 1)
+	historyRepo := mocks.NewQueryHistoryRepository(t)
+	historyRepo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved <- args.Get(1).(*entity.QueryHistory) }).
+		Return(nil)
+	historyRepo.On("Prune", mock.Anything, int64(1), 50).Return(nil).Maybe()
+
+	settings := map[string]string{"max_threads": "4", "max_execution_time": "30", "use_query_cache": "true"}
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("GetSettings", mock.Anything, conn).Return([]entity.CHSetting{
+		{Name: "max_threads", Type: "MaxThreads"},
+		{Name: "max_execution_time", Type: "Seconds"},
+		{Name: "max_memory_usage", Type: "UInt64"},
+		{Name: "use_query_cache", Type: "Bool"},
+		{Name: "readonly", Type: "UInt64", Readonly: 1},
+	}, nil)
+	chClient.On("ExecuteQueryWithResults", mock.Anything, conn, "SELECT 1", entity.QueryOptions{QueryID: "query-1", Settings: settings}).
+		Return(&entity.QueryResult{}, nil).Once()
+
+	uc := usecase.NewConnectionUsecase(repo, historyRepo, nil, chClient, nil, entity.QueryOptions{}, 0)
+
+	_, err := uc.ExecuteQuery(ctx, 1, entity.QueryRequest{QueryID: "query-1", Query: "SELECT 1", Settings: settings})
+	require.NoError(t, err)
+	assert.Equal(t, settings, (<-saved).Settings)
+
+	_, err = uc.ExecuteQuery(ctx, 1, entity.QueryRequest{QueryID: "query-2", Query: "SELECT 1", Settings: map[string]string{
+		"max_memory_usage": "10G",
+		"readonly":         "0",
+		"max_treads":       "4",
+	}})
+	assert.EqualError(t, err, `invalid settings: setting "max_memory_usage": expected a UInt64 value, got "10G"; unknown setting "max_treads"; setting "readonly" cannot be changed`)
+}
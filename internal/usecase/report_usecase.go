@@ -3,15 +3,45 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/rahmatrdn/go-ch-manager/entity"
+	apperr "github.com/rahmatrdn/go-ch-manager/error"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
 	"github.com/rahmatrdn/go-ch-manager/internal/repository/sqlite"
 )
 
+const (
+	slowQueryDefaultLimit = 20
+	slowQueryMaxLimit     = 500
+	// Snapshots nobody refreshed for this long are dropped, every filter keeps its own
+	slowQuerySnapshotTTL = 7 * 24 * time.Hour
+)
+
+// slowQueryRanges are the preset time windows of the slow query report
+var slowQueryRanges = map[string]time.Duration{
+	entity.SlowQueryRange1h:  time.Hour,
+	entity.SlowQueryRange6h:  6 * time.Hour,
+	entity.SlowQueryRange24h: 24 * time.Hour,
+	entity.SlowQueryRange7d:  7 * 24 * time.Hour,
+	entity.SlowQueryRange30d: 30 * 24 * time.Hour,
+}
+
+// slowQuerySortColumns maps each ranking metric to the report column it orders by
+var slowQuerySortColumns = map[string]string{
+	entity.SlowQuerySortMaxDuration:   "max_duration_ms",
+	entity.SlowQuerySortP95Duration:   "p95_duration_ms",
+	entity.SlowQuerySortTotalDuration: "total_duration_ms",
+	entity.SlowQuerySortExecutions:    "executions",
+	entity.SlowQuerySortBytesRead:     "total_bytes_read",
+	entity.SlowQuerySortMemory:        "peak_memory_usage",
+}
+
 type ReportUsecase interface {
-	GetTopSlowQueries(ctx context.Context, connectionID int64, queryKind string, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error)
+	GetTopSlowQueries(ctx context.Context, connectionID int64, filter entity.SlowQueryFilter, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error)
 }
 
 type reportUsecase struct {
@@ -32,18 +62,24 @@ func NewReportUsecase(
 	}
 }
 
-func (u *reportUsecase) GetTopSlowQueries(ctx context.Context, connectionID int64, queryKind string, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error) {
+// GetTopSlowQueries ranks the queries of query_log matching the filter. Each filter has its own snapshot in SQLite,
+// it is returned as is until refreshed.
+func (u *reportUsecase) GetTopSlowQueries(ctx context.Context, connectionID int64, filter entity.SlowQueryFilter, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error) {
+	filter, err := normalizeSlowQueryFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshotKey := slowQuerySnapshotKey(filter)
+
 	// 1. Check SQLite if not forceRefresh
 	if !forceRefresh {
-		existing, err := u.reportRepo.GetSlowQueryReports(ctx, connectionID)
+		existing, err := u.reportRepo.GetSlowQueryReports(ctx, connectionID, snapshotKey)
 		if err != nil {
 			return nil, nil, err
 		}
 		if len(existing) > 0 {
-			// Find max UpdatedAt or CreatedAt as refresh time
+			// All rows of a snapshot are inserted at once, picking one is enough
 			lastRef := existing[0].CreatedAt
-			// Assuming all rows are inserted at once, picking one is enough.
-			// Or we could scan for max.
 			return existing, &lastRef, nil
 		}
 	}
@@ -58,41 +94,9 @@ func (u *reportUsecase) GetTopSlowQueries(ctx context.Context, connectionID int6
 	}
 
 	// 3. Execute Query on ClickHouse
-	query := `
-SELECT
-    query_kind                                 AS query_kind,
-    initial_user                               AS executed_by,
-    any(query)                                 AS sample_query,
-    normalizeQuery(query)                      AS query_normalized,
-    count()                                    AS executions,
-    round(avg(query_duration_ms), 2)           AS avg_duration_ms,
-    quantileTDigest(0.95)(query_duration_ms)   AS p95_duration_ms,
-    max(query_duration_ms)                     AS max_duration_ms,
-    sum(read_rows)                             AS total_rows_read,
-    sum(read_bytes)                            AS total_bytes_read
-FROM system.query_log
-WHERE
-    event_time >= now() - INTERVAL 24 HOUR
-    AND type = 'QueryFinish'
-    AND is_initial_query = 1`
-
-	// Add query kind filter if specified
-	if queryKind != "" && queryKind != "all" {
-		query += fmt.Sprintf("\n    AND query_kind = '%s'", queryKind)
-	}
-
-	query += `
-GROUP BY
-    query_kind,
-    executed_by,
-    query_normalized
-ORDER BY max_duration_ms DESC
-LIMIT 20;
-`
-	res, err := u.chClient.ExecuteQueryWithResults(ctx, conn, query)
+	query, params := slowQueryStatement(filter, time.Now())
+	res, err := u.chClient.ExecuteQueryWithResults(ctx, conn, query, entity.QueryOptions{Parameters: params})
 	if err != nil {
-		// If fails, maybe return existing cache if available?
-		// For now, return error.
 		return nil, nil, err
 	}
 
@@ -100,9 +104,11 @@ LIMIT 20;
 	var reports []*entity.SlowQueryReport
 	now := time.Now()
 
-	for _, row := range res.Rows {
+	for i, row := range res.Rows {
 		report := &entity.SlowQueryReport{
 			ConnectionID:    connectionID,
+			SnapshotKey:     snapshotKey,
+			Rank:            i + 1,
 			QueryKind:       getString(row["query_kind"]),
 			ExecutedBy:      getString(row["executed_by"]),
 			SampleQuery:     getString(row["sample_query"]),
@@ -111,8 +117,10 @@ LIMIT 20;
 			AvgDurationMs:   getFloat64(row["avg_duration_ms"]),
 			P95DurationMs:   getFloat64(row["p95_duration_ms"]),
 			MaxDurationMs:   getFloat64(row["max_duration_ms"]),
+			TotalDurationMs: getFloat64(row["total_duration_ms"]),
 			TotalRowsRead:   getUint64(row["total_rows_read"]),
 			TotalBytesRead:  getUint64(row["total_bytes_read"]),
+			PeakMemoryUsage: getUint64(row["peak_memory_usage"]),
 			LastRefresh:     now,
 			CreatedAt:       now,
 			UpdatedAt:       now,
@@ -120,14 +128,153 @@ LIMIT 20;
 		reports = append(reports, report)
 	}
 
-	// 5. Save to SQLite
-	if err := u.reportRepo.SaveSlowQueryReports(ctx, connectionID, reports); err != nil {
+	// 5. Save to SQLite, dropping the snapshots of filters that are no longer used
+	if err := u.reportRepo.SaveSlowQueryReports(ctx, connectionID, snapshotKey, reports); err != nil {
+		return nil, nil, err
+	}
+	if err := u.reportRepo.PruneSlowQueryReports(ctx, connectionID, now.Add(-slowQuerySnapshotTTL)); err != nil {
 		return nil, nil, err
 	}
 
 	return reports, &now, nil
 }
 
+// normalizeSlowQueryFilter fills in the defaults and refuses what the report cannot run
+func normalizeSlowQueryFilter(f entity.SlowQueryFilter) (entity.SlowQueryFilter, error) {
+	invalid := func(format string, args ...interface{}) (entity.SlowQueryFilter, error) {
+		return f, apperr.CustomError(fmt.Sprintf(format, args...), entity.BAD_REQUEST_MSG, http.StatusBadRequest)
+	}
+
+	if f.QueryKind == "" {
+		f.QueryKind = "all"
+	}
+
+	if f.Range == "" {
+		f.Range = entity.SlowQueryRange24h
+		if !f.From.IsZero() {
+			f.Range = entity.SlowQueryRangeCustom
+		}
+	}
+	if f.Range == entity.SlowQueryRangeCustom {
+		if f.From.IsZero() {
+			return invalid("a custom range needs a from time")
+		}
+		// An open range runs until now, it is only resolved when the report is queried
+		to := f.To
+		if to.IsZero() {
+			to = time.Now()
+		}
+		if !f.From.Before(to) {
+			return invalid("the range must start before it ends")
+		}
+	} else if _, ok := slowQueryRanges[f.Range]; ok {
+		f.From, f.To = time.Time{}, time.Time{}
+	} else {
+		return invalid("unknown range %q", f.Range)
+	}
+
+	if f.Limit == 0 {
+		f.Limit = slowQueryDefaultLimit
+	}
+	if f.Limit < 1 || f.Limit > slowQueryMaxLimit {
+		return invalid("limit must be between 1 and %d", slowQueryMaxLimit)
+	}
+
+	if f.SortBy == "" {
+		f.SortBy = entity.SlowQuerySortMaxDuration
+	}
+	if _, ok := slowQuerySortColumns[f.SortBy]; !ok {
+		return invalid("unknown sort metric %q", f.SortBy)
+	}
+	return f, nil
+}
+
+// slowQuerySnapshotKey names the snapshot of a normalized filter, a custom range is part of it to the second and
+// an open one ends "now", so reloading it finds the same snapshot
+func slowQuerySnapshotKey(f entity.SlowQueryFilter) string {
+	values := url.Values{}
+	values.Set("kind", f.QueryKind)
+	values.Set("range", f.Range)
+	if f.Range == entity.SlowQueryRangeCustom {
+		values.Set("from", strconv.FormatInt(f.From.Unix(), 10))
+		values.Set("to", "now")
+		if !f.To.IsZero() {
+			values.Set("to", strconv.FormatInt(f.To.Unix(), 10))
+		}
+	}
+	values.Set("limit", strconv.Itoa(f.Limit))
+	values.Set("sort", f.SortBy)
+	values.Set("user", f.User)
+	values.Set("database", f.Database)
+	values.Set("table", f.Table)
+	return values.Encode()
+}
+
+// slowQueryStatement builds the report query for a normalized filter, the values are bound as parameters
+func slowQueryStatement(f entity.SlowQueryFilter, now time.Time) (string, map[string]string) {
+	from, to := f.From, f.To
+	if f.Range != entity.SlowQueryRangeCustom {
+		from, to = now.Add(-slowQueryRanges[f.Range]), now
+	}
+	if to.IsZero() {
+		to = now
+	}
+	params := map[string]string{
+		"from": strconv.FormatInt(from.Unix(), 10),
+		"to":   strconv.FormatInt(to.Unix(), 10),
+	}
+
+	query := `
+SELECT
+    query_kind                                 AS query_kind,
+    initial_user                               AS executed_by,
+    any(query)                                 AS sample_query,
+    normalizeQuery(query)                      AS query_normalized,
+    count()                                    AS executions,
+    round(avg(query_duration_ms), 2)           AS avg_duration_ms,
+    quantileTDigest(0.95)(query_duration_ms)   AS p95_duration_ms,
+    max(query_duration_ms)                     AS max_duration_ms,
+    sum(query_duration_ms)                     AS total_duration_ms,
+    sum(read_rows)                             AS total_rows_read,
+    sum(read_bytes)                            AS total_bytes_read,
+    max(memory_usage)                          AS peak_memory_usage
+FROM system.query_log
+WHERE
+    event_date >= toDate(toDateTime({from:Int64}))
+    AND event_time >= toDateTime({from:Int64})
+    AND event_time < toDateTime({to:Int64})
+    AND type = 'QueryFinish'
+    AND is_initial_query = 1`
+
+	if f.QueryKind != "all" {
+		query += "\n    AND query_kind = {kind:String}"
+		params["kind"] = f.QueryKind
+	}
+	if f.User != "" {
+		query += "\n    AND initial_user = {user:String}"
+		params["user"] = f.User
+	}
+	if f.Database != "" {
+		query += "\n    AND has(databases, {database:String})"
+		params["database"] = f.Database
+	}
+	if f.Table != "" {
+		// query_log names tables with their database, a bare name matches it in any database
+		query += "\n    AND arrayExists(t -> t = {table:String} OR endsWith(t, concat('.', {table:String})), tables)"
+		params["table"] = f.Table
+	}
+
+	query += fmt.Sprintf(`
+GROUP BY
+    query_kind,
+    executed_by,
+    query_normalized
+ORDER BY %s DESC
+LIMIT %d
+`, slowQuerySortColumns[f.SortBy], f.Limit)
+	return query, params
+}
+
 // Helpers for type assertion (ClickHouse driver can return various types)
 func getString(v interface{}) string {
 	if s, ok := v.(string); ok {
@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/usecase"
+	"github.com/rahmatrdn/go-ch-manager/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTopSlowQueries(t *testing.T) {
+	conn := &entity.CHConnection{ID: 1}
+	connectionRepo := mocks.NewConnectionRepository(t)
+	connectionRepo.On("FindByID", mock.Anything, int64(1)).Return(conn, nil)
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(6 * time.Hour)
+	snapshotKey := "database=analytics&from=1790812800&kind=Select&limit=50&range=custom&sort=memory&table=events&to=1790834400&user="
+
+	reportRepo := mocks.NewReportRepository(t)
+	reportRepo.On("GetSlowQueryReports", mock.Anything, int64(1), snapshotKey).Return(nil, nil)
+	reportRepo.On("SaveSlowQueryReports", mock.Anything, int64(1), snapshotKey, mock.MatchedBy(func(reports []*entity.SlowQueryReport) bool {
+		return len(reports) == 1 && reports[0].Rank == 1 && reports[0].PeakMemoryUsage == 1<<30 && reports[0].TotalDurationMs == 5000
+	})).Return(nil)
+	reportRepo.On("PruneSlowQueryReports", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+	chClient := mocks.NewClickHouseClient(t)
+	chClient.On("ExecuteQueryWithResults", mock.Anything, conn, mock.MatchedBy(func(query string) bool {
+		return strings.Contains(query, "AND has(databases, {database:String})") &&
+			strings.Contains(query, "AND query_kind = {kind:String}") &&
+			!strings.Contains(query, "initial_user = {user:String}") &&
+			strings.Contains(query, "ORDER BY peak_memory_usage DESC\nLIMIT 50")
+	}), entity.QueryOptions{Parameters: map[string]string{
+		"from": "1790812800", "to": "1790834400", "kind": "Select", "database": "analytics", "table": "events",
+	}}).Return(&entity.QueryResult{Rows: []map[string]interface{}{
+		{"query_kind": "Select", "executions": uint64(4), "total_duration_ms": uint64(5000), "peak_memory_usage": int64(1 << 30)},
+	}}, nil)
+
+	uc := usecase.NewReportUsecase(reportRepo, connectionRepo, chClient)
+
+	reports, lastRefresh, err := uc.GetTopSlowQueries(context.Background(), 1, entity.SlowQueryFilter{
+		QueryKind: "Select", From: from, To: to, Limit: 50, SortBy: entity.SlowQuerySortMemory,
+		Database: "analytics", Table: "events",
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.NotNil(t, lastRefresh)
+	assert.Equal(t, uint64(4), reports[0].Executions)
+}
+
+func TestGetTopSlowQueriesOpenRange(t *testing.T) {
+	// A range without an end is cached as ending now, loading it again does not query the server
+	snapshot := []*entity.SlowQueryReport{{ConnectionID: 1, Rank: 1, CreatedAt: time.Now()}}
+	reportRepo := mocks.NewReportRepository(t)
+	reportRepo.On("GetSlowQueryReports", mock.Anything, int64(1), "database=&from=1790812800&kind=all&limit=20&range=custom&sort=max_duration&table=&to=now&user=").
+		Return(snapshot, nil).Twice()
+
+	uc := usecase.NewReportUsecase(reportRepo, nil, nil)
+
+	for i := 0; i < 2; i++ {
+		reports, _, err := uc.GetTopSlowQueries(context.Background(), 1, entity.SlowQueryFilter{
+			From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, snapshot, reports)
+	}
+}
+
+func TestGetTopSlowQueriesInvalidFilter(t *testing.T) {
+	uc := usecase.NewReportUsecase(nil, nil, nil)
+
+	tests := []struct {
+		filter  entity.SlowQueryFilter
+		wantErr string
+	}{
+		{entity.SlowQueryFilter{Range: "2w"}, `unknown range "2w"`},
+		{entity.SlowQueryFilter{Range: entity.SlowQueryRangeCustom}, "a custom range needs a from time"},
+		{entity.SlowQueryFilter{From: time.Now(), To: time.Now().Add(-time.Hour)}, "the range must start before it ends"},
+		{entity.SlowQueryFilter{Limit: 1000}, "limit must be between 1 and 500"},
+		{entity.SlowQueryFilter{SortBy: "cpu"}, `unknown sort metric "cpu"`},
+	}
+	for _, tt := range tests {
+		_, _, err := uc.GetTopSlowQueries(context.Background(), 1, tt.filter, false)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+}
@@ -0,0 +1,89 @@
+package fixture
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/rahmatrdn/go-ch-manager/entity"
+)
+
+// ClickHouseServer answers the HTTP interface of ClickHouse well enough to open, ping and version a pool. Every
+// request is recorded with the database it was sent for.
+type ClickHouseServer struct {
+	Host string
+	Port int
+
+	mu       sync.Mutex
+	requests []ClickHouseRequest
+}
+
+// ClickHouseRequest is a query received by ClickHouseServer
+type ClickHouseRequest struct {
+	Database string
+	Query    string
+}
+
+// NewClickHouseServer starts a ClickHouseServer closed at the end of the test
+func NewClickHouseServer(t *testing.T) *ClickHouseServer {
+	s := &ClickHouseServer{}
+	server := httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	s.Host = host
+	s.Port, _ = strconv.Atoi(port)
+	return s
+}
+
+// Connection returns a saved connection to the server over HTTP
+func (s *ClickHouseServer) Connection(id int64, database string) *entity.CHConnection {
+	return &entity.CHConnection{ID: id, Host: s.Host, Port: s.Port, Protocol: "http", Database: database}
+}
+
+// Requests returns the queries received so far
+func (s *ClickHouseServer) Requests() []ClickHouseRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ClickHouseRequest(nil), s.requests...)
+}
+
+func (s *ClickHouseServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	query := string(body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, ClickHouseRequest{Database: r.URL.Query().Get("database"), Query: query})
+	s.mu.Unlock()
+
+	block := proto.NewBlock()
+	switch {
+	case strings.Contains(query, "displayName()"):
+		_ = block.AddColumn("displayName()", "String")
+		_ = block.AddColumn("version()", "String")
+		_ = block.AddColumn("revision()", "UInt32")
+		_ = block.AddColumn("timezone()", "String")
+		_ = block.Append("fixture", "24.8.1", uint32(clickhouse.ClientTCPProtocolVersion), "UTC")
+	case strings.Contains(query, "version()"):
+		_ = block.AddColumn("version()", "String")
+		_ = block.Append("24.8.1")
+	default:
+		_ = block.AddColumn("1", "UInt8")
+		_ = block.Append(uint8(1))
+	}
+
+	buffer := new(chproto.Buffer)
+	if err := block.Encode(buffer, clickhouse.ClientTCPProtocolVersion); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(buffer.Buf)
+}
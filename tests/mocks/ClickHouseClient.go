@@ -6,8 +6,10 @@ package mocks
 
 import (
 	"context"
+	"io"
 
 	"github.com/rahmatrdn/go-ch-manager/entity"
+	"github.com/rahmatrdn/go-ch-manager/internal/repository/clickhouse"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -38,9 +40,190 @@ func (_m *ClickHouseClient) EXPECT() *ClickHouseClient_Expecter {
 	return &ClickHouseClient_Expecter{mock: &_m.Mock}
 }
 
+// Close provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) Close() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ClickHouseClient_Close_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Close'
+type ClickHouseClient_Close_Call struct {
+	*mock.Call
+}
+
+// Close is a helper method to define mock.On call
+func (_e *ClickHouseClient_Expecter) Close() *ClickHouseClient_Close_Call {
+	return &ClickHouseClient_Close_Call{Call: _e.mock.On("Close")}
+}
+
+func (_c *ClickHouseClient_Close_Call) Run(run func()) *ClickHouseClient_Close_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_Close_Call) Return(err error) *ClickHouseClient_Close_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ClickHouseClient_Close_Call) RunAndReturn(run func() error) *ClickHouseClient_Close_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DropCaches provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) DropCaches(ctx context.Context, conn *entity.CHConnection) error {
+	ret := _mock.Called(ctx, conn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DropCaches")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection) error); ok {
+		r0 = returnFunc(ctx, conn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ClickHouseClient_DropCaches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DropCaches'
+type ClickHouseClient_DropCaches_Call struct {
+	*mock.Call
+}
+
+// DropCaches is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+func (_e *ClickHouseClient_Expecter) DropCaches(ctx interface{}, conn interface{}) *ClickHouseClient_DropCaches_Call {
+	return &ClickHouseClient_DropCaches_Call{Call: _e.mock.On("DropCaches", ctx, conn)}
+}
+
+func (_c *ClickHouseClient_DropCaches_Call) Run(run func(ctx context.Context, conn *entity.CHConnection)) *ClickHouseClient_DropCaches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_DropCaches_Call) Return(err error) *ClickHouseClient_DropCaches_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ClickHouseClient_DropCaches_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection) error) *ClickHouseClient_DropCaches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExecStatement provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) ExecStatement(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExecStatement")
+	}
+
+	var r0 *entity.QueryStats
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.QueryStats, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) *entity.QueryStats); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.QueryStats)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_ExecStatement_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExecStatement'
+type ClickHouseClient_ExecStatement_Call struct {
+	*mock.Call
+}
+
+// ExecStatement is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ExecStatement(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_ExecStatement_Call {
+	return &ClickHouseClient_ExecStatement_Call{Call: _e.mock.On("ExecStatement", ctx, conn, query, opts)}
+}
+
+func (_c *ClickHouseClient_ExecStatement_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_ExecStatement_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_ExecStatement_Call) Return(queryStats *entity.QueryStats, err error) *ClickHouseClient_ExecStatement_Call {
+	_c.Call.Return(queryStats, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ExecStatement_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error)) *ClickHouseClient_ExecStatement_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ExecuteQueryWithResults provides a mock function for the type ClickHouseClient
-func (_mock *ClickHouseClient) ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryResult, error) {
-	ret := _mock.Called(ctx, conn, query)
+func (_mock *ClickHouseClient) ExecuteQueryWithResults(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryResult, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ExecuteQueryWithResults")
@@ -48,18 +231,18 @@ func (_mock *ClickHouseClient) ExecuteQueryWithResults(ctx context.Context, conn
 
 	var r0 *entity.QueryResult
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) (*entity.QueryResult, error)); ok {
-		return returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.QueryResult, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) *entity.QueryResult); ok {
-		r0 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) *entity.QueryResult); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.QueryResult)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string) error); ok {
-		r1 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -75,11 +258,12 @@ type ClickHouseClient_ExecuteQueryWithResults_Call struct {
 //   - ctx context.Context
 //   - conn *entity.CHConnection
 //   - query string
-func (_e *ClickHouseClient_Expecter) ExecuteQueryWithResults(ctx interface{}, conn interface{}, query interface{}) *ClickHouseClient_ExecuteQueryWithResults_Call {
-	return &ClickHouseClient_ExecuteQueryWithResults_Call{Call: _e.mock.On("ExecuteQueryWithResults", ctx, conn, query)}
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ExecuteQueryWithResults(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_ExecuteQueryWithResults_Call {
+	return &ClickHouseClient_ExecuteQueryWithResults_Call{Call: _e.mock.On("ExecuteQueryWithResults", ctx, conn, query, opts)}
 }
 
-func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string)) *ClickHouseClient_ExecuteQueryWithResults_Call {
+func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_ExecuteQueryWithResults_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -93,10 +277,15 @@ func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) Run(run func(ctx contex
 		if args[2] != nil {
 			arg2 = args[2].(string)
 		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
@@ -107,14 +296,14 @@ func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) Return(queryResult *ent
 	return _c
 }
 
-func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryResult, error)) *ClickHouseClient_ExecuteQueryWithResults_Call {
+func (_c *ClickHouseClient_ExecuteQueryWithResults_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryResult, error)) *ClickHouseClient_ExecuteQueryWithResults_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // ExecuteQueryWithStats provides a mock function for the type ClickHouseClient
-func (_mock *ClickHouseClient) ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryStats, error) {
-	ret := _mock.Called(ctx, conn, query)
+func (_mock *ClickHouseClient) ExecuteQueryWithStats(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ExecuteQueryWithStats")
@@ -122,18 +311,18 @@ func (_mock *ClickHouseClient) ExecuteQueryWithStats(ctx context.Context, conn *
 
 	var r0 *entity.QueryStats
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) (*entity.QueryStats, error)); ok {
-		return returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.QueryStats, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) *entity.QueryStats); ok {
-		r0 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) *entity.QueryStats); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.QueryStats)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string) error); ok {
-		r1 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -149,11 +338,12 @@ type ClickHouseClient_ExecuteQueryWithStats_Call struct {
 //   - ctx context.Context
 //   - conn *entity.CHConnection
 //   - query string
-func (_e *ClickHouseClient_Expecter) ExecuteQueryWithStats(ctx interface{}, conn interface{}, query interface{}) *ClickHouseClient_ExecuteQueryWithStats_Call {
-	return &ClickHouseClient_ExecuteQueryWithStats_Call{Call: _e.mock.On("ExecuteQueryWithStats", ctx, conn, query)}
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ExecuteQueryWithStats(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_ExecuteQueryWithStats_Call {
+	return &ClickHouseClient_ExecuteQueryWithStats_Call{Call: _e.mock.On("ExecuteQueryWithStats", ctx, conn, query, opts)}
 }
 
-func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string)) *ClickHouseClient_ExecuteQueryWithStats_Call {
+func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_ExecuteQueryWithStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -167,10 +357,15 @@ func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) Run(run func(ctx context.
 		if args[2] != nil {
 			arg2 = args[2].(string)
 		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
@@ -181,31 +376,33 @@ func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) Return(queryStats *entity
 	return _c
 }
 
-func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string) (*entity.QueryStats, error)) *ClickHouseClient_ExecuteQueryWithStats_Call {
+func (_c *ClickHouseClient_ExecuteQueryWithStats_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.QueryStats, error)) *ClickHouseClient_ExecuteQueryWithStats_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // ExplainQuery provides a mock function for the type ClickHouseClient
-func (_mock *ClickHouseClient) ExplainQuery(ctx context.Context, conn *entity.CHConnection, query string) (string, error) {
-	ret := _mock.Called(ctx, conn, query)
+func (_mock *ClickHouseClient) ExplainQuery(ctx context.Context, conn *entity.CHConnection, kind string, query string, opts entity.QueryOptions) (*entity.ExplainResult, error) {
+	ret := _mock.Called(ctx, conn, kind, query, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ExplainQuery")
 	}
 
-	var r0 string
+	var r0 *entity.ExplainResult
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) (string, error)); ok {
-		return returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) (*entity.ExplainResult, error)); ok {
+		return returnFunc(ctx, conn, kind, query, opts)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) string); ok {
-		r0 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) *entity.ExplainResult); ok {
+		r0 = returnFunc(ctx, conn, kind, query, opts)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ExplainResult)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string) error); ok {
-		r1 = returnFunc(ctx, conn, query)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, kind, query, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,12 +417,14 @@ type ClickHouseClient_ExplainQuery_Call struct {
 // ExplainQuery is a helper method to define mock.On call
 //   - ctx context.Context
 //   - conn *entity.CHConnection
+//   - kind string
 //   - query string
-func (_e *ClickHouseClient_Expecter) ExplainQuery(ctx interface{}, conn interface{}, query interface{}) *ClickHouseClient_ExplainQuery_Call {
-	return &ClickHouseClient_ExplainQuery_Call{Call: _e.mock.On("ExplainQuery", ctx, conn, query)}
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ExplainQuery(ctx interface{}, conn interface{}, kind interface{}, query interface{}, opts interface{}) *ClickHouseClient_ExplainQuery_Call {
+	return &ClickHouseClient_ExplainQuery_Call{Call: _e.mock.On("ExplainQuery", ctx, conn, kind, query, opts)}
 }
 
-func (_c *ClickHouseClient_ExplainQuery_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string)) *ClickHouseClient_ExplainQuery_Call {
+func (_c *ClickHouseClient_ExplainQuery_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, kind string, query string, opts entity.QueryOptions)) *ClickHouseClient_ExplainQuery_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -239,21 +438,115 @@ func (_c *ClickHouseClient_ExplainQuery_Call) Run(run func(ctx context.Context,
 		if args[2] != nil {
 			arg2 = args[2].(string)
 		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 entity.QueryOptions
+		if args[4] != nil {
+			arg4 = args[4].(entity.QueryOptions)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
+			arg4,
 		)
 	})
 	return _c
 }
 
-func (_c *ClickHouseClient_ExplainQuery_Call) Return(s string, err error) *ClickHouseClient_ExplainQuery_Call {
-	_c.Call.Return(s, err)
+func (_c *ClickHouseClient_ExplainQuery_Call) Return(explainResult *entity.ExplainResult, err error) *ClickHouseClient_ExplainQuery_Call {
+	_c.Call.Return(explainResult, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ExplainQuery_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, kind string, query string, opts entity.QueryOptions) (*entity.ExplainResult, error)) *ClickHouseClient_ExplainQuery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportQuery provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) ExportQuery(ctx context.Context, conn *entity.CHConnection, query string, format string, opts entity.QueryOptions) (io.ReadCloser, error) {
+	ret := _mock.Called(ctx, conn, query, format, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportQuery")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) (io.ReadCloser, error)); ok {
+		return returnFunc(ctx, conn, query, format, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) io.ReadCloser); ok {
+		r0 = returnFunc(ctx, conn, query, format, opts)
+	} else {
+		r0 = ret.Get(0).(io.ReadCloser)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, format, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_ExportQuery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportQuery'
+type ClickHouseClient_ExportQuery_Call struct {
+	*mock.Call
+}
+
+// ExportQuery is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - format string
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ExportQuery(ctx interface{}, conn interface{}, query interface{}, format interface{}, opts interface{}) *ClickHouseClient_ExportQuery_Call {
+	return &ClickHouseClient_ExportQuery_Call{Call: _e.mock.On("ExportQuery", ctx, conn, query, format, opts)}
+}
+
+func (_c *ClickHouseClient_ExportQuery_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, format string, opts entity.QueryOptions)) *ClickHouseClient_ExportQuery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 entity.QueryOptions
+		if args[4] != nil {
+			arg4 = args[4].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
 	return _c
 }
 
-func (_c *ClickHouseClient_ExplainQuery_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string) (string, error)) *ClickHouseClient_ExplainQuery_Call {
+func (_c *ClickHouseClient_ExportQuery_Call) Return(readCloser io.ReadCloser, err error) *ClickHouseClient_ExportQuery_Call {
+	_c.Call.Return(readCloser, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ExportQuery_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, format string, opts entity.QueryOptions) (io.ReadCloser, error)) *ClickHouseClient_ExportQuery_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1090,36 +1383,86 @@ func (_c *ClickHouseClient_GetUsers_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
-// Ping provides a mock function for the type ClickHouseClient
-func (_mock *ClickHouseClient) Ping(ctx context.Context, conn *entity.CHConnection) error {
-	ret := _mock.Called(ctx, conn)
+// Invalidate provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) Invalidate(connectionID int64) {
+	_mock.Called(connectionID)
+	return
+}
+
+// ClickHouseClient_Invalidate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Invalidate'
+type ClickHouseClient_Invalidate_Call struct {
+	*mock.Call
+}
+
+// Invalidate is a helper method to define mock.On call
+//   - connectionID int64
+func (_e *ClickHouseClient_Expecter) Invalidate(connectionID interface{}) *ClickHouseClient_Invalidate_Call {
+	return &ClickHouseClient_Invalidate_Call{Call: _e.mock.On("Invalidate", connectionID)}
+}
+
+func (_c *ClickHouseClient_Invalidate_Call) Run(run func(connectionID int64)) *ClickHouseClient_Invalidate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_Invalidate_Call) Return() *ClickHouseClient_Invalidate_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ClickHouseClient_Invalidate_Call) RunAndReturn(run func(connectionID int64)) *ClickHouseClient_Invalidate_Call {
+	_c.Run(run)
+	return _c
+}
+
+// KillQuery provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) KillQuery(ctx context.Context, conn *entity.CHConnection, queryID string) (bool, error) {
+	ret := _mock.Called(ctx, conn, queryID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Ping")
+		panic("no return value specified for KillQuery")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection) error); ok {
-		r0 = returnFunc(ctx, conn)
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) (bool, error)); ok {
+		return returnFunc(ctx, conn, queryID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string) bool); ok {
+		r0 = returnFunc(ctx, conn, queryID)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string) error); ok {
+		r1 = returnFunc(ctx, conn, queryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// ClickHouseClient_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
-type ClickHouseClient_Ping_Call struct {
+// ClickHouseClient_KillQuery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KillQuery'
+type ClickHouseClient_KillQuery_Call struct {
 	*mock.Call
 }
 
-// Ping is a helper method to define mock.On call
+// KillQuery is a helper method to define mock.On call
 //   - ctx context.Context
 //   - conn *entity.CHConnection
-func (_e *ClickHouseClient_Expecter) Ping(ctx interface{}, conn interface{}) *ClickHouseClient_Ping_Call {
-	return &ClickHouseClient_Ping_Call{Call: _e.mock.On("Ping", ctx, conn)}
+//   - queryID string
+func (_e *ClickHouseClient_Expecter) KillQuery(ctx interface{}, conn interface{}, queryID interface{}) *ClickHouseClient_KillQuery_Call {
+	return &ClickHouseClient_KillQuery_Call{Call: _e.mock.On("KillQuery", ctx, conn, queryID)}
 }
 
-func (_c *ClickHouseClient_Ping_Call) Run(run func(ctx context.Context, conn *entity.CHConnection)) *ClickHouseClient_Ping_Call {
+func (_c *ClickHouseClient_KillQuery_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, queryID string)) *ClickHouseClient_KillQuery_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -1129,20 +1472,512 @@ func (_c *ClickHouseClient_Ping_Call) Run(run func(ctx context.Context, conn *en
 		if args[1] != nil {
 			arg1 = args[1].(*entity.CHConnection)
 		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *ClickHouseClient_Ping_Call) Return(err error) *ClickHouseClient_Ping_Call {
-	_c.Call.Return(err)
+func (_c *ClickHouseClient_KillQuery_Call) Return(b bool, err error) *ClickHouseClient_KillQuery_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *ClickHouseClient_Ping_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection) error) *ClickHouseClient_Ping_Call {
+func (_c *ClickHouseClient_KillQuery_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, queryID string) (bool, error)) *ClickHouseClient_KillQuery_Call {
 	_c.Call.Return(run)
 	return _c
 }
+
+// Ping provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) Ping(ctx context.Context, conn *entity.CHConnection) error {
+	ret := _mock.Called(ctx, conn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection) error); ok {
+		r0 = returnFunc(ctx, conn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ClickHouseClient_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type ClickHouseClient_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+func (_e *ClickHouseClient_Expecter) Ping(ctx interface{}, conn interface{}) *ClickHouseClient_Ping_Call {
+	return &ClickHouseClient_Ping_Call{Call: _e.mock.On("Ping", ctx, conn)}
+}
+
+func (_c *ClickHouseClient_Ping_Call) Run(run func(ctx context.Context, conn *entity.CHConnection)) *ClickHouseClient_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_Ping_Call) Return(err error) *ClickHouseClient_Ping_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ClickHouseClient_Ping_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection) error) *ClickHouseClient_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PoolStats provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) PoolStats() []entity.PoolStats {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PoolStats")
+	}
+
+	var r0 []entity.PoolStats
+	if returnFunc, ok := ret.Get(0).(func() []entity.PoolStats); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.PoolStats)
+		}
+	}
+	return r0
+}
+
+// ClickHouseClient_PoolStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PoolStats'
+type ClickHouseClient_PoolStats_Call struct {
+	*mock.Call
+}
+
+// PoolStats is a helper method to define mock.On call
+func (_e *ClickHouseClient_Expecter) PoolStats() *ClickHouseClient_PoolStats_Call {
+	return &ClickHouseClient_PoolStats_Call{Call: _e.mock.On("PoolStats")}
+}
+
+func (_c *ClickHouseClient_PoolStats_Call) Run(run func()) *ClickHouseClient_PoolStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_PoolStats_Call) Return(poolStatss []entity.PoolStats) *ClickHouseClient_PoolStats_Call {
+	_c.Call.Return(poolStatss)
+	return _c
+}
+
+func (_c *ClickHouseClient_PoolStats_Call) RunAndReturn(run func() []entity.PoolStats) *ClickHouseClient_PoolStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueryRows provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) QueryRows(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (clickhouse.RowStream, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRows")
+	}
+
+	var r0 clickhouse.RowStream
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (clickhouse.RowStream, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) clickhouse.RowStream); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(clickhouse.RowStream)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_QueryRows_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryRows'
+type ClickHouseClient_QueryRows_Call struct {
+	*mock.Call
+}
+
+// QueryRows is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) QueryRows(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_QueryRows_Call {
+	return &ClickHouseClient_QueryRows_Call{Call: _e.mock.On("QueryRows", ctx, conn, query, opts)}
+}
+
+func (_c *ClickHouseClient_QueryRows_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_QueryRows_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_QueryRows_Call) Return(rowStream clickhouse.RowStream, err error) *ClickHouseClient_QueryRows_Call {
+	_c.Call.Return(rowStream, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_QueryRows_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (clickhouse.RowStream, error)) *ClickHouseClient_QueryRows_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResultChecksum provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) ResultChecksum(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.ResultChecksum, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResultChecksum")
+	}
+
+	var r0 *entity.ResultChecksum
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) (*entity.ResultChecksum, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) *entity.ResultChecksum); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ResultChecksum)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_ResultChecksum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResultChecksum'
+type ClickHouseClient_ResultChecksum_Call struct {
+	*mock.Call
+}
+
+// ResultChecksum is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ResultChecksum(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_ResultChecksum_Call {
+	return &ClickHouseClient_ResultChecksum_Call{Call: _e.mock.On("ResultChecksum", ctx, conn, query, opts)}
+}
+
+func (_c *ClickHouseClient_ResultChecksum_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_ResultChecksum_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultChecksum_Call) Return(resultChecksum *entity.ResultChecksum, err error) *ClickHouseClient_ResultChecksum_Call {
+	_c.Call.Return(resultChecksum, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultChecksum_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) (*entity.ResultChecksum, error)) *ClickHouseClient_ResultChecksum_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResultColumns provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) ResultColumns(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) ([]entity.TableSchemaColumn, error) {
+	ret := _mock.Called(ctx, conn, query, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResultColumns")
+	}
+
+	var r0 []entity.TableSchemaColumn
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) ([]entity.TableSchemaColumn, error)); ok {
+		return returnFunc(ctx, conn, query, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) []entity.TableSchemaColumn); ok {
+		r0 = returnFunc(ctx, conn, query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.TableSchemaColumn)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_ResultColumns_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResultColumns'
+type ClickHouseClient_ResultColumns_Call struct {
+	*mock.Call
+}
+
+// ResultColumns is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ResultColumns(ctx interface{}, conn interface{}, query interface{}, opts interface{}) *ClickHouseClient_ResultColumns_Call {
+	return &ClickHouseClient_ResultColumns_Call{Call: _e.mock.On("ResultColumns", ctx, conn, query, opts)}
+}
+
+func (_c *ClickHouseClient_ResultColumns_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions)) *ClickHouseClient_ResultColumns_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entity.QueryOptions
+		if args[3] != nil {
+			arg3 = args[3].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultColumns_Call) Return(tableSchemaColumns []entity.TableSchemaColumn, err error) *ClickHouseClient_ResultColumns_Call {
+	_c.Call.Return(tableSchemaColumns, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultColumns_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, opts entity.QueryOptions) ([]entity.TableSchemaColumn, error)) *ClickHouseClient_ResultColumns_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResultDiff provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) ResultDiff(ctx context.Context, conn *entity.CHConnection, query string, other string, limit int, opts entity.QueryOptions) ([]map[string]interface{}, error) {
+	ret := _mock.Called(ctx, conn, query, other, limit, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResultDiff")
+	}
+
+	var r0 []map[string]interface{}
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, int, entity.QueryOptions) ([]map[string]interface{}, error)); ok {
+		return returnFunc(ctx, conn, query, other, limit, opts)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *entity.CHConnection, string, string, int, entity.QueryOptions) []map[string]interface{}); ok {
+		r0 = returnFunc(ctx, conn, query, other, limit, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]map[string]interface{})
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *entity.CHConnection, string, string, int, entity.QueryOptions) error); ok {
+		r1 = returnFunc(ctx, conn, query, other, limit, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// ClickHouseClient_ResultDiff_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResultDiff'
+type ClickHouseClient_ResultDiff_Call struct {
+	*mock.Call
+}
+
+// ResultDiff is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - query string
+//   - other string
+//   - limit int
+//   - opts entity.QueryOptions
+func (_e *ClickHouseClient_Expecter) ResultDiff(ctx interface{}, conn interface{}, query interface{}, other interface{}, limit interface{}, opts interface{}) *ClickHouseClient_ResultDiff_Call {
+	return &ClickHouseClient_ResultDiff_Call{Call: _e.mock.On("ResultDiff", ctx, conn, query, other, limit, opts)}
+}
+
+func (_c *ClickHouseClient_ResultDiff_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, query string, other string, limit int, opts entity.QueryOptions)) *ClickHouseClient_ResultDiff_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 int
+		if args[4] != nil {
+			arg4 = args[4].(int)
+		}
+		var arg5 entity.QueryOptions
+		if args[5] != nil {
+			arg5 = args[5].(entity.QueryOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultDiff_Call) Return(m []map[string]interface{}, err error) *ClickHouseClient_ResultDiff_Call {
+	_c.Call.Return(m, err)
+	return _c
+}
+
+func (_c *ClickHouseClient_ResultDiff_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, query string, other string, limit int, opts entity.QueryOptions) ([]map[string]interface{}, error)) *ClickHouseClient_ResultDiff_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TestConnection provides a mock function for the type ClickHouseClient
+func (_mock *ClickHouseClient) TestConnection(ctx context.Context, conn *entity.CHConnection, result *entity.ConnectionTestResult) {
+	_mock.Called(ctx, conn, result)
+	return
+}
+
+// ClickHouseClient_TestConnection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TestConnection'
+type ClickHouseClient_TestConnection_Call struct {
+	*mock.Call
+}
+
+// TestConnection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conn *entity.CHConnection
+//   - result *entity.ConnectionTestResult
+func (_e *ClickHouseClient_Expecter) TestConnection(ctx interface{}, conn interface{}, result interface{}) *ClickHouseClient_TestConnection_Call {
+	return &ClickHouseClient_TestConnection_Call{Call: _e.mock.On("TestConnection", ctx, conn, result)}
+}
+
+func (_c *ClickHouseClient_TestConnection_Call) Run(run func(ctx context.Context, conn *entity.CHConnection, result *entity.ConnectionTestResult)) *ClickHouseClient_TestConnection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *entity.CHConnection
+		if args[1] != nil {
+			arg1 = args[1].(*entity.CHConnection)
+		}
+		var arg2 *entity.ConnectionTestResult
+		if args[2] != nil {
+			arg2 = args[2].(*entity.ConnectionTestResult)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *ClickHouseClient_TestConnection_Call) Return() *ClickHouseClient_TestConnection_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ClickHouseClient_TestConnection_Call) RunAndReturn(run func(ctx context.Context, conn *entity.CHConnection, result *entity.ConnectionTestResult)) *ClickHouseClient_TestConnection_Call {
+	_c.Run(run)
+	return _c
+}
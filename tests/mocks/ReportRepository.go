@@ -6,6 +6,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/rahmatrdn/go-ch-manager/entity"
 	mock "github.com/stretchr/testify/mock"
@@ -39,8 +40,8 @@ func (_m *ReportRepository) EXPECT() *ReportRepository_Expecter {
 }
 
 // GetSlowQueryReports provides a mock function for the type ReportRepository
-func (_mock *ReportRepository) GetSlowQueryReports(ctx context.Context, connectionID int64) ([]*entity.SlowQueryReport, error) {
-	ret := _mock.Called(ctx, connectionID)
+func (_mock *ReportRepository) GetSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string) ([]*entity.SlowQueryReport, error) {
+	ret := _mock.Called(ctx, connectionID, snapshotKey)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetSlowQueryReports")
@@ -48,18 +49,18 @@ func (_mock *ReportRepository) GetSlowQueryReports(ctx context.Context, connecti
 
 	var r0 []*entity.SlowQueryReport
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]*entity.SlowQueryReport, error)); ok {
-		return returnFunc(ctx, connectionID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) ([]*entity.SlowQueryReport, error)); ok {
+		return returnFunc(ctx, connectionID, snapshotKey)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []*entity.SlowQueryReport); ok {
-		r0 = returnFunc(ctx, connectionID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) []*entity.SlowQueryReport); ok {
+		r0 = returnFunc(ctx, connectionID, snapshotKey)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*entity.SlowQueryReport)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = returnFunc(ctx, connectionID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, connectionID, snapshotKey)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -74,11 +75,12 @@ type ReportRepository_GetSlowQueryReports_Call struct {
 // GetSlowQueryReports is a helper method to define mock.On call
 //   - ctx context.Context
 //   - connectionID int64
-func (_e *ReportRepository_Expecter) GetSlowQueryReports(ctx interface{}, connectionID interface{}) *ReportRepository_GetSlowQueryReports_Call {
-	return &ReportRepository_GetSlowQueryReports_Call{Call: _e.mock.On("GetSlowQueryReports", ctx, connectionID)}
+//   - snapshotKey string
+func (_e *ReportRepository_Expecter) GetSlowQueryReports(ctx interface{}, connectionID interface{}, snapshotKey interface{}) *ReportRepository_GetSlowQueryReports_Call {
+	return &ReportRepository_GetSlowQueryReports_Call{Call: _e.mock.On("GetSlowQueryReports", ctx, connectionID, snapshotKey)}
 }
 
-func (_c *ReportRepository_GetSlowQueryReports_Call) Run(run func(ctx context.Context, connectionID int64)) *ReportRepository_GetSlowQueryReports_Call {
+func (_c *ReportRepository_GetSlowQueryReports_Call) Run(run func(ctx context.Context, connectionID int64, snapshotKey string)) *ReportRepository_GetSlowQueryReports_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -88,9 +90,14 @@ func (_c *ReportRepository_GetSlowQueryReports_Call) Run(run func(ctx context.Co
 		if args[1] != nil {
 			arg1 = args[1].(int64)
 		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
@@ -101,22 +108,85 @@ func (_c *ReportRepository_GetSlowQueryReports_Call) Return(slowQueryReports []*
 	return _c
 }
 
-func (_c *ReportRepository_GetSlowQueryReports_Call) RunAndReturn(run func(ctx context.Context, connectionID int64) ([]*entity.SlowQueryReport, error)) *ReportRepository_GetSlowQueryReports_Call {
+func (_c *ReportRepository_GetSlowQueryReports_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, snapshotKey string) ([]*entity.SlowQueryReport, error)) *ReportRepository_GetSlowQueryReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PruneSlowQueryReports provides a mock function for the type ReportRepository
+func (_mock *ReportRepository) PruneSlowQueryReports(ctx context.Context, connectionID int64, before time.Time) error {
+	ret := _mock.Called(ctx, connectionID, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneSlowQueryReports")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = returnFunc(ctx, connectionID, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ReportRepository_PruneSlowQueryReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PruneSlowQueryReports'
+type ReportRepository_PruneSlowQueryReports_Call struct {
+	*mock.Call
+}
+
+// PruneSlowQueryReports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - connectionID int64
+//   - before time.Time
+func (_e *ReportRepository_Expecter) PruneSlowQueryReports(ctx interface{}, connectionID interface{}, before interface{}) *ReportRepository_PruneSlowQueryReports_Call {
+	return &ReportRepository_PruneSlowQueryReports_Call{Call: _e.mock.On("PruneSlowQueryReports", ctx, connectionID, before)}
+}
+
+func (_c *ReportRepository_PruneSlowQueryReports_Call) Run(run func(ctx context.Context, connectionID int64, before time.Time)) *ReportRepository_PruneSlowQueryReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *ReportRepository_PruneSlowQueryReports_Call) Return(err error) *ReportRepository_PruneSlowQueryReports_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ReportRepository_PruneSlowQueryReports_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, before time.Time) error) *ReportRepository_PruneSlowQueryReports_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SaveSlowQueryReports provides a mock function for the type ReportRepository
-func (_mock *ReportRepository) SaveSlowQueryReports(ctx context.Context, connectionID int64, reports []*entity.SlowQueryReport) error {
-	ret := _mock.Called(ctx, connectionID, reports)
+func (_mock *ReportRepository) SaveSlowQueryReports(ctx context.Context, connectionID int64, snapshotKey string, reports []*entity.SlowQueryReport) error {
+	ret := _mock.Called(ctx, connectionID, snapshotKey, reports)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveSlowQueryReports")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, []*entity.SlowQueryReport) error); ok {
-		r0 = returnFunc(ctx, connectionID, reports)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, []*entity.SlowQueryReport) error); ok {
+		r0 = returnFunc(ctx, connectionID, snapshotKey, reports)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -131,12 +201,13 @@ type ReportRepository_SaveSlowQueryReports_Call struct {
 // SaveSlowQueryReports is a helper method to define mock.On call
 //   - ctx context.Context
 //   - connectionID int64
+//   - snapshotKey string
 //   - reports []*entity.SlowQueryReport
-func (_e *ReportRepository_Expecter) SaveSlowQueryReports(ctx interface{}, connectionID interface{}, reports interface{}) *ReportRepository_SaveSlowQueryReports_Call {
-	return &ReportRepository_SaveSlowQueryReports_Call{Call: _e.mock.On("SaveSlowQueryReports", ctx, connectionID, reports)}
+func (_e *ReportRepository_Expecter) SaveSlowQueryReports(ctx interface{}, connectionID interface{}, snapshotKey interface{}, reports interface{}) *ReportRepository_SaveSlowQueryReports_Call {
+	return &ReportRepository_SaveSlowQueryReports_Call{Call: _e.mock.On("SaveSlowQueryReports", ctx, connectionID, snapshotKey, reports)}
 }
 
-func (_c *ReportRepository_SaveSlowQueryReports_Call) Run(run func(ctx context.Context, connectionID int64, reports []*entity.SlowQueryReport)) *ReportRepository_SaveSlowQueryReports_Call {
+func (_c *ReportRepository_SaveSlowQueryReports_Call) Run(run func(ctx context.Context, connectionID int64, snapshotKey string, reports []*entity.SlowQueryReport)) *ReportRepository_SaveSlowQueryReports_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -146,14 +217,19 @@ func (_c *ReportRepository_SaveSlowQueryReports_Call) Run(run func(ctx context.C
 		if args[1] != nil {
 			arg1 = args[1].(int64)
 		}
-		var arg2 []*entity.SlowQueryReport
+		var arg2 string
 		if args[2] != nil {
-			arg2 = args[2].([]*entity.SlowQueryReport)
+			arg2 = args[2].(string)
+		}
+		var arg3 []*entity.SlowQueryReport
+		if args[3] != nil {
+			arg3 = args[3].([]*entity.SlowQueryReport)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
@@ -164,7 +240,7 @@ func (_c *ReportRepository_SaveSlowQueryReports_Call) Return(err error) *ReportR
 	return _c
 }
 
-func (_c *ReportRepository_SaveSlowQueryReports_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, reports []*entity.SlowQueryReport) error) *ReportRepository_SaveSlowQueryReports_Call {
+func (_c *ReportRepository_SaveSlowQueryReports_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, snapshotKey string, reports []*entity.SlowQueryReport) error) *ReportRepository_SaveSlowQueryReports_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -40,8 +40,8 @@ func (_m *ReportUsecase) EXPECT() *ReportUsecase_Expecter {
 }
 
 // GetTopSlowQueries provides a mock function for the type ReportUsecase
-func (_mock *ReportUsecase) GetTopSlowQueries(ctx context.Context, connectionID int64, queryKind string, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error) {
-	ret := _mock.Called(ctx, connectionID, queryKind, forceRefresh)
+func (_mock *ReportUsecase) GetTopSlowQueries(ctx context.Context, connectionID int64, filter entity.SlowQueryFilter, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error) {
+	ret := _mock.Called(ctx, connectionID, filter, forceRefresh)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTopSlowQueries")
@@ -50,25 +50,25 @@ func (_mock *ReportUsecase) GetTopSlowQueries(ctx context.Context, connectionID
 	var r0 []*entity.SlowQueryReport
 	var r1 *time.Time
 	var r2 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, bool) ([]*entity.SlowQueryReport, *time.Time, error)); ok {
-		return returnFunc(ctx, connectionID, queryKind, forceRefresh)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entity.SlowQueryFilter, bool) ([]*entity.SlowQueryReport, *time.Time, error)); ok {
+		return returnFunc(ctx, connectionID, filter, forceRefresh)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, bool) []*entity.SlowQueryReport); ok {
-		r0 = returnFunc(ctx, connectionID, queryKind, forceRefresh)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entity.SlowQueryFilter, bool) []*entity.SlowQueryReport); ok {
+		r0 = returnFunc(ctx, connectionID, filter, forceRefresh)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*entity.SlowQueryReport)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, bool) *time.Time); ok {
-		r1 = returnFunc(ctx, connectionID, queryKind, forceRefresh)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, entity.SlowQueryFilter, bool) *time.Time); ok {
+		r1 = returnFunc(ctx, connectionID, filter, forceRefresh)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*time.Time)
 		}
 	}
-	if returnFunc, ok := ret.Get(2).(func(context.Context, int64, string, bool) error); ok {
-		r2 = returnFunc(ctx, connectionID, queryKind, forceRefresh)
+	if returnFunc, ok := ret.Get(2).(func(context.Context, int64, entity.SlowQueryFilter, bool) error); ok {
+		r2 = returnFunc(ctx, connectionID, filter, forceRefresh)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -83,13 +83,13 @@ type ReportUsecase_GetTopSlowQueries_Call struct {
 // GetTopSlowQueries is a helper method to define mock.On call
 //   - ctx context.Context
 //   - connectionID int64
-//   - queryKind string
+//   - filter entity.SlowQueryFilter
 //   - forceRefresh bool
-func (_e *ReportUsecase_Expecter) GetTopSlowQueries(ctx interface{}, connectionID interface{}, queryKind interface{}, forceRefresh interface{}) *ReportUsecase_GetTopSlowQueries_Call {
-	return &ReportUsecase_GetTopSlowQueries_Call{Call: _e.mock.On("GetTopSlowQueries", ctx, connectionID, queryKind, forceRefresh)}
+func (_e *ReportUsecase_Expecter) GetTopSlowQueries(ctx interface{}, connectionID interface{}, filter interface{}, forceRefresh interface{}) *ReportUsecase_GetTopSlowQueries_Call {
+	return &ReportUsecase_GetTopSlowQueries_Call{Call: _e.mock.On("GetTopSlowQueries", ctx, connectionID, filter, forceRefresh)}
 }
 
-func (_c *ReportUsecase_GetTopSlowQueries_Call) Run(run func(ctx context.Context, connectionID int64, queryKind string, forceRefresh bool)) *ReportUsecase_GetTopSlowQueries_Call {
+func (_c *ReportUsecase_GetTopSlowQueries_Call) Run(run func(ctx context.Context, connectionID int64, filter entity.SlowQueryFilter, forceRefresh bool)) *ReportUsecase_GetTopSlowQueries_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -99,9 +99,9 @@ func (_c *ReportUsecase_GetTopSlowQueries_Call) Run(run func(ctx context.Context
 		if args[1] != nil {
 			arg1 = args[1].(int64)
 		}
-		var arg2 string
+		var arg2 entity.SlowQueryFilter
 		if args[2] != nil {
-			arg2 = args[2].(string)
+			arg2 = args[2].(entity.SlowQueryFilter)
 		}
 		var arg3 bool
 		if args[3] != nil {
@@ -122,7 +122,7 @@ func (_c *ReportUsecase_GetTopSlowQueries_Call) Return(slowQueryReports []*entit
 	return _c
 }
 
-func (_c *ReportUsecase_GetTopSlowQueries_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, queryKind string, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error)) *ReportUsecase_GetTopSlowQueries_Call {
+func (_c *ReportUsecase_GetTopSlowQueries_Call) RunAndReturn(run func(ctx context.Context, connectionID int64, filter entity.SlowQueryFilter, forceRefresh bool) ([]*entity.SlowQueryReport, *time.Time, error)) *ReportUsecase_GetTopSlowQueries_Call {
 	_c.Call.Return(run)
 	return _c
 }